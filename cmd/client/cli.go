@@ -0,0 +1,660 @@
+package main
+
+import (
+	clientapi "clienthttps/internal/client/clientAPI"
+	"clienthttps/internal/client/libre"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	"golang.org/x/term"
+)
+
+// Коды завершения приложения
+const (
+	exitOK          = 0 // команда выполнена
+	exitError       = 1 // ошибка выполнения команды
+	exitUsage       = 2 // неверные аргументы командной строки или параметры окружения
+	exitAuth        = 3 // неверное имя пользователя или пароль
+	exitUnreachable = 4 // сервер недоступен
+	exitInterrupted = 5 // выполнение прервано сигналом или по времени
+	exitPartial     = 6 // команда fleet или sync -once выполнена не для всех объектов
+)
+
+// Файл переменных окружения по умолчанию
+const defaultEnvFile = "./configs/.env"
+
+// Ошибка сохранения файла экспорта
+var errSaveFile = errors.New("ошибка при сохранении данных в файл")
+
+// Команда приложения
+type command struct {
+	name  string                  // имя команды
+	usage string                  // краткое описание
+	run   func(args []string) int // выполнение с аргументами после имени, возвращается код завершения
+}
+
+// Список команд приложения.
+func commands() []command {
+	return []command{
+		{name: "status", usage: "вывод состояния сервера", run: cmdStatus},
+		{name: "count", usage: "количество архивных строк за день (-date)", run: cmdCount},
+		{name: "export", usage: "выгрузка архивных данных в файл (-from, -to, -format, -out)", run: cmdExport},
+		{name: "login-test", usage: "проверка регистрации на сервере", run: cmdLoginTest},
+		{name: "fleet", usage: "выполнение status или export по профилям серверов (fleet status|export -profiles)", run: cmdFleet},
+		{name: "sync", usage: "опрос серверов и загрузка недостающих дней в локальный архив (-dir, -since, -once)", run: cmdSync},
+		{name: "menu", usage: "интерактивное меню (по умолчанию)", run: cmdMenu},
+	}
+}
+
+// Ошибка аргументов командной строки или параметров окружения
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// Создание ошибки аргументов.
+func usagef(format string, a ...any) error {
+	return &usageError{err: fmt.Errorf(format, a...)}
+}
+
+// Код завершения по ошибке выполнения команды. Прерыванием считается только завершение контекста команды:
+// превышение времени ожидания отдельного запроса означает недоступность сервера.
+//
+// Параметры:
+//
+// err - ошибка выполнения команды
+// ctxErr - ошибка контекста команды (nil - контекст не завершён или ещё не создан)
+func exitCode(err, ctxErr error) int {
+
+	var errUsage *usageError
+	var errCancel *clientapi.CanceledError
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &errUsage), errors.Is(err, clientapi.ErrInvalidArg), errors.Is(err, clientapi.ErrBadDate),
+		errors.Is(err, clientapi.ErrCertificate):
+		return exitUsage
+	case ctxErr != nil, errors.As(err, &errCancel):
+		return exitInterrupted
+	case errors.Is(err, clientapi.ErrUnauthorized):
+		return exitAuth
+	case errors.Is(err, clientapi.ErrNetwork):
+		return exitUnreachable
+	}
+	return exitError
+}
+
+// Разбор командной строки и выполнение команды. Без команды запускается интерактивное меню.
+// Возвращается код завершения.
+//
+// Параметры:
+//
+// args - аргументы командной строки без имени приложения
+func runCLI(args []string) int {
+
+	err := loadEnv()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка:", err)
+		return exitCode(err, nil)
+	}
+
+	if len(args) == 0 {
+		return cmdMenu(nil)
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		printUsage()
+		return exitOK
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Неизвестная команда: {%s}\n\n", args[0])
+	printUsage()
+	return exitUsage
+}
+
+// Вывод списка команд и кодов завершения.
+func printUsage() {
+
+	out := os.Stderr
+	fmt.Fprintln(out, "Использование: clientHTTPS [команда] [флаги]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Команды:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(out, "  %-11s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Флаги команды: clientHTTPS <команда> -h")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Коды завершения:")
+	fmt.Fprintln(out, "  0 - команда выполнена")
+	fmt.Fprintln(out, "  1 - ошибка выполнения команды")
+	fmt.Fprintln(out, "  2 - неверные аргументы или параметры окружения")
+	fmt.Fprintln(out, "  3 - неверное имя пользователя или пароль")
+	fmt.Fprintln(out, "  4 - сервер недоступен")
+	fmt.Fprintln(out, "  5 - выполнение прервано")
+	fmt.Fprintln(out, "  6 - команда fleet или sync -once выполнена не для всех объектов")
+}
+
+// Чтение переменных окружения из файла HTTPS_CLIENT_ENV_FILE (по умолчанию ./configs/.env).
+// Заданные в окружении процесса переменные не переопределяются. Отсутствие файла по умолчанию
+// ошибкой не является: параметры могут задаваться только окружением и флагами. Возвращается ошибка.
+func loadEnv() error {
+
+	path := os.Getenv("HTTPS_CLIENT_ENV_FILE")
+	explicit := path != ""
+	if !explicit {
+		path = defaultEnvFile
+	}
+
+	err := godotenv.Load(path)
+	if err != nil && (explicit || !errors.Is(err, fs.ErrNotExist)) {
+		return usagef("ошибка чтения переменных окружения {%s}: {%v}", path, err)
+	}
+	return nil
+}
+
+// Параметры подключения к серверу. Значения по умолчанию берутся из профиля сервера и переменных окружения.
+type connFlags struct {
+	profile      string        // профиль сервера из файла настроек
+	host         string        // адрес сервера
+	port         string        // порт сервера
+	ca           string        // публичный ключ сервера
+	user         string        // имя пользователя
+	passwordFile string        // файл с паролем пользователя
+	timeout      time.Duration // ограничение времени выполнения команды (0 - без ограничения)
+
+	password string // пароль пользователя (пусто - HTTPS_CLIENT_PASSWORD)
+	pageSize int    // количество строк в одном запросе (0 - HTTPS_CLIENT_PAGE_SIZE)
+	workers  int    // количество параллельных запросов (0 - HTTPS_CLIENT_WORKERS)
+	noPrompt bool   // без ввода недостающих данных пользователя в терминале
+}
+
+// Регистрация флагов подключения.
+//
+// Параметры:
+//
+// flags - набор флагов команды
+func (c *connFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&c.profile, "profile", os.Getenv("HTTPS_CLIENT_PROFILE"), "профиль сервера из файла настроек (HTTPS_CLIENT_PROFILE, файл HTTPS_CLIENT_CONFIG)")
+	flags.StringVar(&c.host, "host", os.Getenv("HTTPS_SERVER_IP"), "адрес HTTPS сервера (HTTPS_SERVER_IP)")
+	flags.StringVar(&c.port, "port", os.Getenv("HTTPS_SERVER_PORT"), "порт HTTPS сервера (HTTPS_SERVER_PORT)")
+	flags.StringVar(&c.ca, "ca", os.Getenv("HTTPS_SERVER_KEY_PUBLIC"), "публичный ключ HTTPS сервера (HTTPS_SERVER_KEY_PUBLIC)")
+	flags.StringVar(&c.user, "user", os.Getenv("HTTPS_CLIENT_USER"), "имя пользователя (HTTPS_CLIENT_USER)")
+	flags.StringVar(&c.passwordFile, "password-file", os.Getenv("HTTPS_CLIENT_PASSWORD_FILE"), "файл с паролем пользователя (HTTPS_CLIENT_PASSWORD_FILE, иначе HTTPS_CLIENT_PASSWORD)")
+	flags.DurationVar(&c.timeout, "timeout", 0, "ограничение времени выполнения команды (0 - без ограничения)")
+}
+
+// Адрес сервера вида https://host:port.
+func (c *connFlags) baseURL() string {
+	return "https://" + c.host + ":" + c.port
+}
+
+// Имя и пароль пользователя. Пароль читается из файла или переменной окружения HTTPS_CLIENT_PASSWORD.
+// Недостающие данные вводятся в терминале, без терминала возвращается ошибка аргументов.
+func (c *connFlags) credentials() (usr clientapi.UserLogin, err error) {
+
+	usr.Name = c.user
+	usr.Password = c.password
+	if usr.Password == "" {
+		usr.Password = os.Getenv("HTTPS_CLIENT_PASSWORD")
+	}
+
+	if c.passwordFile != "" {
+		data, err := os.ReadFile(c.passwordFile)
+		if err != nil {
+			return usr, usagef("ошибка чтения файла пароля: {%v}", err)
+		}
+		usr.Password = strings.TrimRight(string(data), "\r\n")
+	}
+
+	if usr.Name != "" && usr.Password != "" {
+		return usr, nil
+	}
+
+	if c.noPrompt || !term.IsTerminal(int(syscall.Stdin)) {
+		return usr, usagef("нет данных пользователя: задайте -user и -password-file или HTTPS_CLIENT_USER и HTTPS_CLIENT_PASSWORD")
+	}
+
+	err = typeUserData(&usr)
+	if err != nil {
+		return usr, fmt.Errorf("ошибка ввода данных пользователя: {%v}", err)
+	}
+	return usr, nil
+}
+
+// Разбор флагов команды. Возвращается признак продолжения и код завершения.
+//
+// Параметры:
+//
+// fs - набор флагов команды
+// args - аргументы после имени команды
+func parseFlags(fs *flag.FlagSet, args []string) (ok bool, code int) {
+
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return false, exitOK
+	}
+	if err != nil {
+		return false, exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Лишние аргументы команды %s: {%s}\n", fs.Name(), strings.Join(fs.Args(), " "))
+		return false, exitUsage
+	}
+	return true, exitOK
+}
+
+// Контекст команды: отмена по Ctrl-C или SIGTERM и ограничение времени выполнения.
+//
+// Параметры:
+//
+// timeout - ограничение времени выполнения (0 - без ограничения)
+func commandCtx(timeout time.Duration) (context.Context, context.CancelFunc) {
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	if timeout <= 0 {
+		return ctx, stop
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// Создание клиента сервера с параметрами из переменных окружения и регистрация пользователя.
+// Возвращается указатель на клиента и ошибка.
+//
+// Параметры:
+//
+// ctx - контекст команды
+// conn - параметры подключения
+// progress - вывод хода выгрузки в терминал
+func connect(ctx context.Context, conn *connFlags, progress bool) (*clientapi.Client, error) {
+
+	client, err := newClient(conn, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	err = login(ctx, client, conn)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// Создание клиента сервера с параметрами из переменных окружения без регистрации пользователя.
+// Возвращается указатель на клиента и ошибка.
+//
+// Параметры:
+//
+// conn - параметры подключения
+// progress - вывод хода выгрузки в терминал
+func newClient(conn *connFlags, progress bool) (*clientapi.Client, error) {
+
+	if conn.host == "" || conn.port == "" {
+		return nil, usagef("не задан адрес сервера: задайте -host и -port или HTTPS_SERVER_IP и HTTPS_SERVER_PORT")
+	}
+
+	// Параметры выгрузки частей
+	opts, err := queueOptions()
+	if err != nil {
+		return nil, &usageError{err: fmt.Errorf("ошибка параметров выгрузки: {%w}", err)}
+	}
+
+	// Повторы запросов при временных сбоях связи
+	retry := clientapi.DefaultRetryPolicy()
+	if v := os.Getenv("HTTPS_CLIENT_RETRY_ATTEMPTS"); v != "" {
+		retry.MaxAttempts, err = strconv.Atoi(v)
+		if err != nil {
+			return nil, usagef("значение HTTPS_CLIENT_RETRY_ATTEMPTS не является числом: {%s}", v)
+		}
+	}
+
+	if conn.workers > 0 {
+		opts = append(opts, clientapi.WithWorkers(conn.workers))
+	}
+	if conn.pageSize > 0 {
+		opts = append(opts, clientapi.WithPageSize(conn.pageSize))
+	}
+
+	opts = append(opts,
+		clientapi.WithCACert(conn.ca),
+		clientapi.WithRetry(retry),
+		clientapi.WithRequestTimeout(requestTimeout),
+	)
+	if progress {
+		opts = append(opts, clientapi.WithProgress(clientapi.PrintProgress))
+	}
+
+	client, err := clientapi.NewClient(conn.baseURL(), opts...)
+	if err != nil {
+		return nil, &usageError{err: fmt.Errorf("ошибка создания https клиента: {%w}", err)}
+	}
+	return client, nil
+}
+
+// Регистрация пользователя на сервере и получение токена. Возвращается ошибка.
+//
+// Параметры:
+//
+// ctx - контекст команды
+// client - клиент сервера
+// conn - параметры подключения
+func login(ctx context.Context, client *clientapi.Client, conn *connFlags) error {
+
+	usr, err := conn.credentials()
+	if err != nil {
+		return err
+	}
+
+	err = client.LoginCtx(ctx, usr.Name, usr.Password)
+	switch {
+	case errors.Is(err, clientapi.ErrUnauthorized):
+		return fmt.Errorf("неверное имя пользователя или пароль: {%w}", err)
+	case errors.Is(err, clientapi.ErrCertificate):
+		return fmt.Errorf("сертификат сервера не соответствует публичному ключу {%s}: {%w}", conn.ca, err)
+	case errors.Is(err, clientapi.ErrNetwork):
+		return fmt.Errorf("сервер недоступен: {%w}", err)
+	case err != nil:
+		return fmt.Errorf("ошибка регистрации на сервере: {%w}", err)
+	}
+	return nil
+}
+
+// Завершение команды с выводом ошибки. Возвращается код завершения.
+//
+// Параметры:
+//
+// err - ошибка выполнения команды
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, "Ошибка:", err)
+	return exitCode(err, nil)
+}
+
+// Завершение команды с выводом ошибки, полученной в контексте команды. Возвращается код завершения.
+//
+// Параметры:
+//
+// ctx - контекст команды
+// err - ошибка выполнения команды
+func failCtx(ctx context.Context, err error) int {
+	fmt.Fprintln(os.Stderr, "Ошибка:", err)
+	return exitCode(err, ctx.Err())
+}
+
+// Команда status - вывод состояния сервера.
+func cmdStatus(args []string) int {
+
+	var conn connFlags
+	var asJSON bool
+
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	conn.register(flags)
+	flags.BoolVar(&asJSON, "json", false, "вывод в формате JSON")
+	if ok, code := parseFlags(flags, args); !ok {
+		return code
+	}
+	if _, err := conn.resolve(flags); err != nil {
+		return fail(err)
+	}
+
+	ctx, stop := commandCtx(conn.timeout)
+	defer stop()
+
+	client, err := connect(ctx, &conn, false)
+	if err != nil {
+		return failCtx(ctx, err)
+	}
+
+	statusSrv, err := client.StatusCtx(ctx)
+	if err != nil {
+		return failCtx(ctx, fmt.Errorf("ошибка при запросе состояния сервера: {%w}", err))
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(statusSrv)
+	} else {
+		err = showStatusServer(statusSrv)
+	}
+	if err != nil {
+		return failCtx(ctx, err)
+	}
+	return exitOK
+}
+
+// Команда count - вывод количества архивных строк за день.
+func cmdCount(args []string) int {
+
+	var conn connFlags
+	var date string
+
+	flags := flag.NewFlagSet("count", flag.ContinueOnError)
+	conn.register(flags)
+	flags.StringVar(&date, "date", "", "дата архивных данных (YYYY-MM-DD)")
+	if ok, code := parseFlags(flags, args); !ok {
+		return code
+	}
+	if _, err := conn.resolve(flags); err != nil {
+		return fail(err)
+	}
+	if date == "" {
+		return fail(usagef("не задана дата: -date YYYY-MM-DD"))
+	}
+
+	ctx, stop := commandCtx(conn.timeout)
+	defer stop()
+
+	client, err := connect(ctx, &conn, false)
+	if err != nil {
+		return failCtx(ctx, err)
+	}
+
+	cnt, err := client.CountRowsCtx(ctx, date)
+	if err != nil {
+		return failCtx(ctx, fmt.Errorf("ошибка при запросе количества строк: {%w}", err))
+	}
+
+	fmt.Println(cnt)
+	return exitOK
+}
+
+// Команда export - выгрузка архивных данных за диапазон дат в файл.
+// В стандартный вывод выводится имя созданного файла.
+func cmdExport(args []string) int {
+
+	var conn connFlags
+	var from, to, format string
+	var progress bool
+	out := output()
+
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	conn.register(flags)
+	flags.StringVar(&from, "from", "", "начальная дата экспорта (YYYY-MM-DD)")
+	flags.StringVar(&to, "to", "", "конечная дата экспорта (YYYY-MM-DD, по умолчанию - начальная)")
+	flags.StringVar(&format, "format", defaultFormat(), "формат файла ("+strings.Join(libre.Formats(), ", ")+"; HTTPS_CLIENT_EXPORT_FORMAT)")
+	flags.StringVar(&out.Dir, "out", out.Dir, "директория файлов экспорта (HTTPS_CLIENT_OUTPUT_DIR)")
+	flags.StringVar(&out.Template, "name", out.Template, "шаблон имени файла (HTTPS_CLIENT_NAME_TEMPLATE)")
+	flags.BoolVar(&progress, "progress", false, "вывод хода выгрузки")
+	if ok, code := parseFlags(flags, args); !ok {
+		return code
+	}
+	p, err := conn.resolve(flags)
+	if err != nil {
+		return fail(err)
+	}
+	fromProfile(flags, "format", &format, p.Output.Format)
+	fromProfile(flags, "out", &out.Dir, p.Output.Dir)
+	fromProfile(flags, "name", &out.Template, p.Output.Name)
+	if from == "" {
+		return fail(usagef("не задана начальная дата: -from YYYY-MM-DD"))
+	}
+	if to == "" {
+		to = from
+	}
+
+	exp, err := exporter(format)
+	if err != nil {
+		return fail(&usageError{err: err})
+	}
+
+	ctx, stop := commandCtx(conn.timeout)
+	defer stop()
+
+	client, err := connect(ctx, &conn, progress)
+	if err != nil {
+		return failCtx(ctx, err)
+	}
+
+	fileName, cnt, err := exportRange(ctx, client, exp, out, from, to)
+	if err != nil {
+		return failCtx(ctx, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Записано строк {%d}\n", cnt)
+	fmt.Println(fileName)
+	return exitOK
+}
+
+// Команда login-test - проверка регистрации на сервере.
+func cmdLoginTest(args []string) int {
+
+	var conn connFlags
+
+	flags := flag.NewFlagSet("login-test", flag.ContinueOnError)
+	conn.register(flags)
+	if ok, code := parseFlags(flags, args); !ok {
+		return code
+	}
+	if _, err := conn.resolve(flags); err != nil {
+		return fail(err)
+	}
+
+	ctx, stop := commandCtx(conn.timeout)
+	defer stop()
+
+	_, err := connect(ctx, &conn, false)
+	if err != nil {
+		return failCtx(ctx, err)
+	}
+
+	fmt.Println("Регистрация пользователя выполнена")
+	return exitOK
+}
+
+// Команда menu - интерактивное меню.
+func cmdMenu(args []string) int {
+
+	var conn connFlags
+
+	flags := flag.NewFlagSet("menu", flag.ContinueOnError)
+	conn.register(flags)
+	if ok, code := parseFlags(flags, args); !ok {
+		return code
+	}
+	p, err := conn.resolve(flags)
+	if err != nil {
+		return fail(err)
+	}
+
+	// Файлы экспорта меню: значения профиля поверх переменных окружения
+	out, format := output(), defaultFormat()
+	fromProfile(flags, "out", &out.Dir, p.Output.Dir)
+	fromProfile(flags, "name", &out.Template, p.Output.Name)
+	fromProfile(flags, "format", &format, p.Output.Format)
+
+	ctx, stop := commandCtx(conn.timeout)
+	client, err := connect(ctx, &conn, true)
+	if err != nil {
+		defer stop()
+		return failCtx(ctx, err)
+	}
+	stop()
+	fmt.Println("Регистрация пользователя выполнена")
+	fmt.Println()
+
+	err = run(client, out, format)
+	if err != nil {
+		return fail(err)
+	}
+	return exitOK
+}
+
+// Выгрузка архивных данных за диапазон дат в файл. Строки записываются в файл по мере приёма частей.
+// Возвращается имя файла, количество записанных строк и ошибка: ошибка выгрузки имеет приоритет
+// перед ошибкой сохранения, ошибка сохранения оборачивает errSaveFile.
+//
+// Параметры:
+//
+// ctx - контекст выгрузки
+// client - клиент сервера
+// exp - формат экспорта
+// out - размещение и имя файла
+// from, to - начальная и конечная даты
+func exportRange(ctx context.Context, client *clientapi.Client, exp libre.Exporter, out libre.Output, from, to string) (fileName string, cnt int, err error) {
+
+	// Время запуска сервера для сводки файла
+	var timeStart string
+	if statusSrv, err := client.StatusCtx(ctx); err == nil {
+		timeStart = statusSrv.TimeStart
+	}
+
+	var errStream error
+	var cntStr int
+	rows := func(yield func(clientapi.DataEl, error) bool) {
+		for row, errRow := range client.StreamRangeCount(ctx, from, to, &cntStr) {
+			errStream = errRow
+			if !yield(row, errRow) {
+				return
+			}
+		}
+	}
+	ds := libre.Dataset{
+		StartDate:  from,
+		EndDate:    to,
+		Rows:       rows,
+		Server:     client.BaseURL(),
+		ExportDate: time.Now(),
+		TimeStart:  timeStart,
+		Expected:   &cntStr,
+	}
+
+	fileName, cnt, err = out.Save(exp, ds)
+	if errStream != nil {
+		return "", cnt, errStream
+	}
+	if err != nil {
+		return "", cnt, fmt.Errorf("%w: {%w}", errSaveFile, err)
+	}
+	return fileName, cnt, nil
+}
@@ -0,0 +1,510 @@
+package main
+
+import (
+	clientapi "clienthttps/internal/client/clientAPI"
+	"clienthttps/internal/client/libre"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Имитация сервера BlackBox с архивом за один день
+func newTestServer(t *testing.T, date string, rows []clientapi.DataEl) *httptest.Server {
+	t.Helper()
+
+	const token = "1234567890"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/registration", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if string(b) != "test 123" {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(clientapi.TokenT{Token: token})
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(clientapi.RxStatusSrv{TimeStart: "22-05-2025 02:18:15"})
+	})
+	mux.HandleFunc("/cntstr", func(w http.ResponseWriter, r *http.Request) {
+		var rx clientapi.DateNameT
+		json.NewDecoder(r.Body).Decode(&rx)
+		cnt := 0
+		if rx.Date == date {
+			cnt = len(rows)
+		}
+		json.NewEncoder(w).Encode(clientapi.CntStrT{CntStr: strconv.Itoa(cnt)})
+	})
+	mux.HandleFunc("/partdatadb", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		numbReq, _ := strconv.Atoi(q.Get("numbReg"))
+		limit, _ := strconv.Atoi(q.Get("strLimit"))
+		offset, _ := strconv.Atoi(q.Get("strOffSet"))
+		json.NewEncoder(w).Encode(clientapi.PartDataDB{NumbReq: numbReq, Data: rows[offset:min(offset+limit, len(rows))]})
+	})
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/registration" && r.Header.Get("authorization") != token {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	// Параметры подключения через переменные окружения
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	t.Setenv("HTTPS_SERVER_IP", u.Hostname())
+	t.Setenv("HTTPS_SERVER_PORT", u.Port())
+	t.Setenv("HTTPS_SERVER_KEY_PUBLIC", writeCA(t, srv))
+	t.Setenv("HTTPS_CLIENT_USER", "test")
+	t.Setenv("HTTPS_CLIENT_PASSWORD", "123")
+	t.Setenv("HTTPS_CLIENT_RETRY_ATTEMPTS", "1")
+
+	return srv
+}
+
+// Запись публичного ключа сервера во временный файл. Возвращается путь к файлу.
+func writeCA(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+
+	ca := filepath.Join(t.TempDir(), "server.crt")
+	err := os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600)
+	require.NoError(t, err)
+	return ca
+}
+
+// Выполнение команды с перехватом стандартного вывода. Возвращается код завершения и вывод.
+func runCaptured(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+
+	f, err := os.CreateTemp(t.TempDir(), "stdout")
+	require.NoError(t, err)
+	defer f.Close()
+
+	stdout := os.Stdout
+	os.Stdout = f
+	code := runCLI(args)
+	os.Stdout = stdout
+
+	out, err := os.ReadFile(f.Name())
+	require.NoError(t, err)
+	return code, string(out)
+}
+
+// Команды - ошибки аргументов
+func Test_runCLI_Usage(t *testing.T) {
+
+	t.Setenv("HTTPS_SERVER_IP", "127.0.0.1")
+	t.Setenv("HTTPS_SERVER_PORT", "1")
+	t.Setenv("HTTPS_CLIENT_USER", "")
+	t.Setenv("HTTPS_CLIENT_PASSWORD", "")
+
+	argData := []struct {
+		testName string
+		args     []string
+		want     int
+	}{
+		{testName: "справка", args: []string{"help"}, want: exitOK},
+		{testName: "справка команды", args: []string{"export", "-h"}, want: exitOK},
+		{testName: "неизвестная команда", args: []string{"upload"}, want: exitUsage},
+		{testName: "неизвестный флаг", args: []string{"status", "-verbose"}, want: exitUsage},
+		{testName: "лишние аргументы", args: []string{"status", "now"}, want: exitUsage},
+		{testName: "count без даты", args: []string{"count"}, want: exitUsage},
+		{testName: "export без начальной даты", args: []string{"export", "-to", "2025-05-18"}, want: exitUsage},
+		{testName: "неизвестный формат", args: []string{"export", "-from", "2025-05-18", "-format", "pdf"}, want: exitUsage},
+		{testName: "нет адреса сервера", args: []string{"login-test", "-host", ""}, want: exitUsage},
+		{testName: "нет публичного ключа", args: []string{"login-test", "-ca", filepath.Join(t.TempDir(), "none.crt")}, want: exitUsage},
+		{testName: "sync с неверной датой", args: []string{"sync", "-since", "18.05.2025"}, want: exitUsage},
+		{testName: "sync без периода опроса", args: []string{"sync", "-interval", "0s"}, want: exitUsage},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+			code, _ := runCaptured(t, tt.args...)
+			assert.Equal(t, tt.want, code)
+		})
+	}
+
+	// Файл переменных окружения, заданный явно, должен существовать
+	t.Setenv("HTTPS_CLIENT_ENV_FILE", filepath.Join(t.TempDir(), "none.env"))
+	code, _ := runCaptured(t, "help")
+	assert.Equal(t, exitUsage, code)
+}
+
+// Команды - выполнение на сервере
+func Test_runCLI_Commands(t *testing.T) {
+
+	date := "2025-05-18"
+	rows := make([]clientapi.DataEl, 0, 250)
+	for i := 0; i < 250; i++ {
+		rows = append(rows, clientapi.DataEl{Name: "Dev1.HR.Tag", Value: strconv.Itoa(i), Qual: "1", TimeStamp: fmt.Sprintf("2025-05-18T03:01:%02d+07:00", i%60)})
+	}
+	srv := newTestServer(t, date, rows)
+
+	// Регистрация
+	code, out := runCaptured(t, "login-test")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, out, "Регистрация пользователя выполнена")
+
+	// Пароль из файла имеет приоритет перед переменной окружения
+	pass := filepath.Join(t.TempDir(), "pass")
+	require.NoError(t, os.WriteFile(pass, []byte("wrong\n"), 0o600))
+	code, _ = runCaptured(t, "login-test", "-password-file", pass)
+	assert.Equal(t, exitAuth, code)
+
+	// Состояние сервера
+	code, out = runCaptured(t, "status", "-json")
+	require.Equal(t, exitOK, code)
+	var status clientapi.RxStatusSrv
+	require.NoError(t, json.Unmarshal([]byte(out), &status))
+	assert.Equal(t, "22-05-2025 02:18:15", status.TimeStart)
+
+	// Количество строк
+	code, out = runCaptured(t, "count", "-date", date)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "250\n", out)
+
+	code, _ = runCaptured(t, "count", "-date", "18.05.2025")
+	assert.Equal(t, exitUsage, code, "дата не в формате YYYY-MM-DD")
+
+	// Выгрузка в файл
+	dir := filepath.Join(t.TempDir(), "export")
+	code, out = runCaptured(t, "export", "-from", date, "-format", "csv", "-out", dir, "-name", "{date}")
+	require.Equal(t, exitOK, code)
+	fileName := strings.TrimSpace(out)
+	assert.Equal(t, filepath.Join(dir, date+".csv"), fileName)
+
+	data, err := os.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(data)), "\n"), 251, "заголовок и все строки")
+
+	// Сервер недоступен
+	srv.Close()
+	code, _ = runCaptured(t, "status")
+	assert.Equal(t, exitUnreachable, code)
+}
+
+// Код завершения по ошибке
+func Test_exitCode(t *testing.T) {
+
+	argData := []struct {
+		testName string
+		err      error
+		ctxErr   error
+		want     int
+	}{
+		{testName: "нет ошибки", err: nil, want: exitOK},
+		{testName: "аргументы", err: usagef("нет даты"), want: exitUsage},
+		{testName: "неверная дата", err: fmt.Errorf("count -> %w", clientapi.ErrBadDate), want: exitUsage},
+		{testName: "авторизация", err: fmt.Errorf("login -> %w", clientapi.ErrUnauthorized), want: exitAuth},
+		{testName: "сеть", err: fmt.Errorf("status -> %w", clientapi.ErrNetwork), want: exitUnreachable},
+		{testName: "сертификат", err: fmt.Errorf("login -> %w", clientapi.ErrCertificate), want: exitUsage},
+		{testName: "прерывание", err: &clientapi.CanceledError{Received: 1, Total: 2}, want: exitInterrupted},
+		{testName: "время ожидания запроса", err: fmt.Errorf("status -> %w: %w", clientapi.ErrNetwork, context.DeadlineExceeded), want: exitUnreachable},
+		{testName: "время выполнения команды", err: fmt.Errorf("status -> %w: %w", clientapi.ErrNetwork, context.DeadlineExceeded), ctxErr: context.DeadlineExceeded, want: exitInterrupted},
+		{testName: "сигнал", err: fmt.Errorf("status -> %w: %w", clientapi.ErrNetwork, context.Canceled), ctxErr: context.Canceled, want: exitInterrupted},
+		{testName: "сохранение", err: fmt.Errorf("%w: {диск заполнен}", errSaveFile), want: exitError},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+			assert.Equal(t, tt.want, exitCode(tt.err, tt.ctxErr))
+		})
+	}
+}
+
+// Флаги подключения - значения профиля, переменных окружения и командной строки
+func Test_connFlags_resolve(t *testing.T) {
+
+	t.Setenv("HTTPS_SERVER_IP", "10.0.0.1")
+	t.Setenv("HTTPS_SERVER_PORT", "8443")
+	t.Setenv("HTTPS_SERVER_KEY_PUBLIC", "env.crt")
+	t.Setenv("HTTPS_CLIENT_USER", "envuser")
+	t.Setenv("HTTPS_CLIENT_PASSWORD_FILE", "env.pass")
+	t.Setenv("HTTPS_CLIENT_PROFILE", "")
+	t.Setenv("SITE_PASSWORD", "secret")
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.yaml")
+	text := "profiles:\n  site:\n    host: 10.0.0.2\n    port: 9443\n    ca: site.crt\n" +
+		"    credentials:\n      user: siteuser\n      password_env: SITE_PASSWORD\n    page_size: 50\n    workers: 2\n" +
+		"  bare:\n    host: 10.0.0.3\n    port: 7443\n"
+	require.NoError(t, os.WriteFile(config, []byte(text), 0o600))
+	t.Setenv("HTTPS_CLIENT_CONFIG", config)
+
+	parse := func(args ...string) (connFlags, error) {
+		var conn connFlags
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		conn.register(flags)
+		require.NoError(t, flags.Parse(args))
+		_, err := conn.resolve(flags)
+		return conn, err
+	}
+
+	// Значения профиля поверх переменных окружения, флаги поверх профиля
+	conn, err := parse("-profile", "site", "-port", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", conn.host)
+	assert.Equal(t, "1", conn.port)
+	assert.Equal(t, filepath.Join(dir, "site.crt"), conn.ca)
+	assert.Equal(t, "siteuser", conn.user)
+	assert.Equal(t, "secret", conn.password)
+	assert.Empty(t, conn.passwordFile, "пароль профиля заменяет файл пароля из окружения")
+	assert.Equal(t, 50, conn.pageSize)
+	assert.Equal(t, 2, conn.workers)
+
+	// Незаданные в профиле значения - из переменных окружения
+	conn, err = parse("-profile", "bare")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.3", conn.host)
+	assert.Equal(t, "env.crt", conn.ca)
+	assert.Equal(t, "envuser", conn.user)
+	assert.Equal(t, "env.pass", conn.passwordFile)
+
+	// Без профиля - переменные окружения, окружение процесса не меняется
+	conn, err = parse()
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", conn.host)
+	assert.Equal(t, "8443", os.Getenv("HTTPS_SERVER_PORT"))
+
+	_, err = parse("-profile", "pump")
+	assert.Equal(t, exitUsage, exitCode(err, nil), "неизвестный профиль")
+
+	// Пустая переменная пароля профиля - ошибка, а не пароль из окружения
+	t.Setenv("SITE_PASSWORD", "")
+	t.Setenv("HTTPS_CLIENT_PASSWORD", "other")
+	_, err = parse("-profile", "site")
+	assert.ErrorContains(t, err, "SITE_PASSWORD")
+	assert.Equal(t, exitUsage, exitCode(err, nil), "нет пароля профиля")
+
+	conn, err = parse("-profile", "site", "-password-file", "site.pass")
+	require.NoError(t, err, "файл пароля из командной строки заменяет пароль профиля")
+	assert.Equal(t, "site.pass", conn.passwordFile)
+}
+
+// Команды - выбор профиля сервера из файла настроек
+func Test_runCLI_Profile(t *testing.T) {
+
+	srv := newTestServer(t, "2025-05-18", nil)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	t.Setenv("HTTPS_CLIENT_PASSWORD_FILE", "")
+	t.Setenv("HTTPS_CLIENT_PROFILE", "")
+
+	// Переменные окружения указывают на недоступный сервер
+	t.Setenv("HTTPS_SERVER_PORT", "1")
+	t.Setenv("HTTPS_CLIENT_PASSWORD", "")
+	t.Setenv("SITE_PASSWORD", "123")
+
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	text := fmt.Sprintf("profiles:\n  site:\n    host: %s\n    port: %s\n    ca: %s\n    credentials:\n      user: test\n      password_env: SITE_PASSWORD\n    page_size: 50\n",
+		u.Hostname(), u.Port(), os.Getenv("HTTPS_SERVER_KEY_PUBLIC"))
+	require.NoError(t, os.WriteFile(config, []byte(text), 0o600))
+	t.Setenv("HTTPS_CLIENT_CONFIG", config)
+
+	code, _ := runCaptured(t, "login-test", "-profile", "site")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "1", os.Getenv("HTTPS_SERVER_PORT"), "профиль не меняет окружение процесса")
+
+	code, _ = runCaptured(t, "login-test", "-profile", "pump")
+	assert.Equal(t, exitUsage, code, "неизвестный профиль")
+
+	// Без профиля используются переменные окружения
+	t.Setenv("HTTPS_CLIENT_PASSWORD", "123")
+	code, _ = runCaptured(t, "login-test")
+	assert.Equal(t, exitUnreachable, code)
+
+	// Флаги имеют приоритет перед профилем
+	code, _ = runCaptured(t, "login-test", "-profile", "site", "-port", "1")
+	assert.Equal(t, exitUnreachable, code)
+}
+
+// Команда fleet - выгрузка по нескольким серверам с отчётом по объектам
+func Test_runCLI_Fleet(t *testing.T) {
+
+	date := "2025-05-18"
+	rows := []clientapi.DataEl{{Name: "Dev1.HR.Tag", Value: "1", Qual: "1", TimeStamp: "2025-05-18T03:01:02+07:00"}}
+
+	// Два доступных сервера, третий остановлен
+	servers := make([]*httptest.Server, 3)
+	for i := range servers {
+		servers[i] = newTestServer(t, date, rows)
+	}
+	servers[2].Close()
+	t.Setenv("HTTPS_CLIENT_PASSWORD", "")
+	t.Setenv("HTTPS_CLIENT_PASSWORD_FILE", "")
+	t.Setenv("FLEET_PASSWORD", "123")
+	t.Setenv("FLEET_WRONG", "321")
+
+	var text strings.Builder
+	text.WriteString("profiles:\n")
+	for i, name := range []string{"boiler1", "boiler2", "compressor", "pump"} {
+		srv, password := servers[min(i, 2)], "FLEET_PASSWORD"
+		if name == "pump" {
+			srv, password = servers[0], "FLEET_WRONG"
+		}
+		u, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		fmt.Fprintf(&text, "  %s:\n    host: %s\n    port: %s\n    ca: %s\n    credentials:\n      user: test\n      password_env: %s\n",
+			name, u.Hostname(), u.Port(), writeCA(t, srv), password)
+	}
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(text.String()), 0o600))
+	t.Setenv("HTTPS_CLIENT_CONFIG", config)
+
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.json")
+	code, out := runCaptured(t, "fleet", "export", "-from", date, "-format", "csv", "-out", dir, "-name", "{date}", "-workers", "2", "-report", report)
+	assert.Equal(t, exitPartial, code)
+	assert.Contains(t, out, "Выполнено {2}, ошибка {1}, недоступно {1}, всего {4}")
+
+	// Файлы каждого сервера в своей директории
+	for _, name := range []string{"boiler1", "boiler2"} {
+		assert.FileExists(t, filepath.Join(dir, name, date+".csv"))
+	}
+	assert.NoDirExists(t, filepath.Join(dir, "compressor"))
+
+	data, err := os.ReadFile(report)
+	require.NoError(t, err)
+	var rep struct {
+		Results []struct {
+			Target string `json:"target"`
+			Status string `json:"status"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(data, &rep))
+	require.Len(t, rep.Results, 4)
+	got := make(map[string]string)
+	for _, res := range rep.Results {
+		got[res.Target] = res.Status
+	}
+	assert.Equal(t, map[string]string{"boiler1": "succeeded", "boiler2": "succeeded", "compressor": "unreachable", "pump": "failed"}, got)
+
+	// Состояние выбранных серверов
+	code, _ = runCaptured(t, "fleet", "status", "-profiles", "boiler1, boiler2", "-out", dir)
+	assert.Equal(t, exitOK, code)
+	assert.FileExists(t, filepath.Join(dir, "boiler2", "status.json"))
+
+	// Ошибки аргументов
+	code, _ = runCaptured(t, "fleet", "status", "-profiles", "boiler9")
+	assert.Equal(t, exitUsage, code)
+	code, _ = runCaptured(t, "fleet", "export")
+	assert.Equal(t, exitUsage, code)
+	code, _ = runCaptured(t, "fleet", "upload")
+	assert.Equal(t, exitUsage, code)
+}
+
+// Команды fleet и sync - профиль по умолчанию не переносится на другие профили
+func Test_runCLI_FleetDefaultProfile(t *testing.T) {
+
+	srv := newTestServer(t, "2025-05-18", nil)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	envDir, aDir := t.TempDir(), t.TempDir()
+	t.Setenv("HTTPS_CLIENT_OUTPUT_DIR", envDir)
+	t.Setenv("HTTPS_CLIENT_PASSWORD_FILE", "")
+	t.Setenv("HTTPS_CLIENT_PROFILE", "")
+	t.Setenv("A_PASSWORD", "secretA")
+
+	// Профиль a - другой сервер, ключ и пользователь; профиль b - только адрес, остальное из окружения
+	text := fmt.Sprintf("default: a\nprofiles:\n"+
+		"  a:\n    host: 127.0.0.1\n    port: 1\n    ca: a.crt\n    credentials:\n      user: alice\n      password_env: A_PASSWORD\n    output:\n      dir: %s\n"+
+		"  b:\n    host: %s\n    port: %s\n", aDir, u.Hostname(), u.Port())
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(text), 0o600))
+	t.Setenv("HTTPS_CLIENT_CONFIG", config)
+
+	code, out := runCaptured(t, "fleet", "status", "-profiles", "b")
+	assert.Equalf(t, exitOK, code, "отчёт: %s", out)
+	assert.FileExists(t, filepath.Join(envDir, "b", "status.json"))
+	assert.NoDirExists(t, filepath.Join(aDir, "b"))
+
+	code, out = runCaptured(t, "sync", "-once", "-profiles", "b", "-dir", t.TempDir(), "-since", "2025-05-18", "-until", "2025-05-18")
+	assert.Equalf(t, exitOK, code, "отчёт: %s", out)
+}
+
+// Команда sync - загрузка недостающих дней и повторный опрос без загрузки
+func Test_runCLI_Sync(t *testing.T) {
+
+	date := "2025-05-18"
+	rows := []clientapi.DataEl{
+		{Name: "Dev1.HR.Tag", Value: "1", Qual: "1", TimeStamp: "2025-05-18T03:01:02+07:00"},
+		{Name: "Dev1.HR.Tag", Value: "2", Qual: "1", TimeStamp: "2025-05-18T03:01:03+07:00"},
+	}
+	srv := newTestServer(t, date, rows)
+	t.Setenv("HTTPS_CLIENT_CONFIG", "")
+	dir := t.TempDir()
+
+	// Загрузка дней диапазона
+	code, out := runCaptured(t, "sync", "-once", "-dir", dir, "-format", "csv", "-since", "2025-05-16", "-until", "2025-05-19")
+	require.Equal(t, exitOK, code)
+	assert.Contains(t, out, "дней 4, строк 2")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{date + ".csv", "sync-state.json"}, names)
+
+	// Загруженные дни повторно не запрашиваются
+	code, out = runCaptured(t, "sync", "-once", "-dir", dir, "-format", "csv", "-since", "2025-05-16", "-until", "2025-05-19")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, out, "дней 0, строк 0")
+
+	// Опрос до остановки по времени
+	code, _ = runCaptured(t, "sync", "-dir", dir, "-format", "csv", "-since", "2025-05-16", "-until", "2025-05-20", "-interval", "10ms", "-timeout", "300ms")
+	assert.Equal(t, exitOK, code)
+	data, err := os.ReadFile(filepath.Join(dir, "sync-state.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "2025-05-20")
+
+	// Сервер недоступен
+	srv.Close()
+	code, out = runCaptured(t, "sync", "-once", "-dir", dir, "-format", "csv", "-since", "2025-05-16", "-until", "2025-05-21")
+	assert.Equal(t, exitPartial, code)
+	assert.Contains(t, out, "недоступно {1}")
+}
+
+// Меню - ошибка запроса состояния возвращает в меню, закрытый ввод завершает работу
+func Test_run_Menu(t *testing.T) {
+
+	srv := newTestServer(t, "2025-05-18", nil)
+
+	conn := connFlags{host: os.Getenv("HTTPS_SERVER_IP"), port: os.Getenv("HTTPS_SERVER_PORT"), ca: os.Getenv("HTTPS_SERVER_KEY_PUBLIC"), user: "test", password: "123", noPrompt: true}
+	client, err := connect(context.Background(), &conn, false)
+	require.NoError(t, err)
+	srv.Close()
+
+	input := filepath.Join(t.TempDir(), "stdin")
+	require.NoError(t, os.WriteFile(input, []byte("1\n\n1\n"), 0o600))
+	f, err := os.Open(input)
+	require.NoError(t, err)
+	defer f.Close()
+
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+
+	assert.NoError(t, run(client, libre.Output{Dir: t.TempDir()}, libre.FormatCSV))
+}
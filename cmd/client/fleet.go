@@ -0,0 +1,276 @@
+package main
+
+import (
+	clientapi "clienthttps/internal/client/clientAPI"
+	"clienthttps/internal/client/config"
+	"clienthttps/internal/client/fleet"
+	"clienthttps/internal/client/libre"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Количество одновременно обслуживаемых серверов по умолчанию
+const defaultFleetWorkers = 4
+
+// Команда fleet - выполнение status или export по списку профилей серверов с ограничением
+// количества одновременно обслуживаемых серверов. Файлы каждого сервера записываются в его директорию,
+// по завершении выводится отчёт по объектам.
+func cmdFleet(args []string) int {
+
+	if len(args) == 0 || (args[0] != "status" && args[0] != "export") {
+		fmt.Fprintln(os.Stderr, "Использование: clientHTTPS fleet status|export [флаги]")
+		return exitUsage
+	}
+	action := args[0]
+
+	var names, report, from, to, format string
+	var out libre.Output
+	var workers int
+	var timeout time.Duration
+
+	flags := flag.NewFlagSet("fleet "+action, flag.ContinueOnError)
+	flags.StringVar(&names, "profiles", "", "имена профилей через запятую (пусто - все профили файла настроек)")
+	flags.IntVar(&workers, "workers", defaultFleetWorkers, "количество одновременно обслуживаемых серверов")
+	flags.StringVar(&out.Dir, "out", os.Getenv("HTTPS_CLIENT_OUTPUT_DIR"), "базовая директория файлов для профилей без output.dir (HTTPS_CLIENT_OUTPUT_DIR)")
+	flags.StringVar(&report, "report", "", "файл отчёта в формате JSON")
+	flags.DurationVar(&timeout, "timeout", 0, "ограничение времени выполнения команды (0 - без ограничения)")
+	if action == "export" {
+		flags.StringVar(&from, "from", "", "начальная дата экспорта (YYYY-MM-DD)")
+		flags.StringVar(&to, "to", "", "конечная дата экспорта (YYYY-MM-DD, по умолчанию - начальная)")
+		flags.StringVar(&format, "format", "", "формат файла ("+strings.Join(libre.Formats(), ", ")+"; по умолчанию - формат профиля)")
+		flags.StringVar(&out.Template, "name", os.Getenv("HTTPS_CLIENT_NAME_TEMPLATE"), "шаблон имени файла для профилей без output.name (HTTPS_CLIENT_NAME_TEMPLATE)")
+	}
+	if ok, code := parseFlags(flags, args[1:]); !ok {
+		return code
+	}
+	if action == "export" && from == "" {
+		return fail(usagef("не задана начальная дата: -from YYYY-MM-DD"))
+	}
+	if to == "" {
+		to = from
+	}
+	if workers < 1 {
+		return fail(usagef("количество одновременно обслуживаемых серверов меньше 1: {%d}", workers))
+	}
+
+	// Профили серверов
+	cfg, err := loadConfig()
+	if err != nil {
+		return fail(err)
+	}
+	if cfg == nil {
+		return fail(usagef("нет файла настроек с профилями серверов {%s}", defaultConfigFile))
+	}
+	targets := cfg.Names()
+	if names != "" {
+		targets = strings.Split(names, ",")
+		for i, name := range targets {
+			targets[i] = strings.TrimSpace(name)
+			if _, err := cfg.Profile(targets[i]); err != nil {
+				return fail(&usageError{err: err})
+			}
+		}
+	}
+
+	// Проверка форматов до обращения к серверам
+	for _, name := range targets {
+		if action != "export" {
+			break
+		}
+		p, _ := cfg.Profile(name)
+		if _, err := exporter(fleetFormat(p, format)); err != nil {
+			return fail(&usageError{err: fmt.Errorf("профиль {%s}: %w", name, err)})
+		}
+	}
+
+	ctx, stop := commandCtx(timeout)
+	defer stop()
+
+	job := func(ctx context.Context, name string) (string, error) {
+
+		p, _ := cfg.Profile(name)
+		conn, err := profileConn(p)
+		if err != nil {
+			return "", err
+		}
+		dst := fleetOutput(p, out)
+
+		client, err := connect(ctx, &conn, false)
+		if err != nil {
+			return "", err
+		}
+
+		if action == "status" {
+			return writeStatus(ctx, client, dst.Dir)
+		}
+
+		exp, err := exporter(fleetFormat(p, format))
+		if err != nil {
+			return "", err
+		}
+		fileName, cnt, err := exportRange(ctx, client, exp, dst, from, to)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s (строк %d)", fileName, cnt), nil
+	}
+
+	runner := fleet.Runner{
+		Workers: workers,
+		OnDone: func(res fleet.Result) {
+			fmt.Fprintf(os.Stderr, "%s: %s (%s)\n", res.Target, res.Status, res.Duration.Round(time.Millisecond))
+		},
+	}
+	rep := runner.Run(ctx, targets, job)
+
+	err = rep.WriteText(os.Stdout)
+	if err != nil {
+		return failCtx(ctx, err)
+	}
+	if report != "" {
+		err = writeReport(report, rep)
+		if err != nil {
+			return failCtx(ctx, err)
+		}
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		return exitInterrupted
+	case !rep.OK():
+		return exitPartial
+	}
+	return exitOK
+}
+
+// Параметры подключения по профилю сервера. Незаданные в профиле значения берутся из переменных окружения
+// (.env и окружение процесса), профиль по умолчанию к ним не применяется. Возвращаются параметры
+// подключения и ошибка, если не задана названная в профиле переменная окружения с паролем.
+//
+// Параметры:
+//
+// p - профиль сервера
+func profileConn(p config.Profile) (connFlags, error) {
+
+	password, err := p.Credentials.Password()
+	if err != nil {
+		return connFlags{}, &usageError{err: fmt.Errorf("профиль {%s}: %w", p.Name, err)}
+	}
+
+	conn := connFlags{
+		profile:      p.Name,
+		host:         p.Host,
+		port:         p.Port,
+		ca:           p.CA,
+		user:         p.Credentials.User,
+		passwordFile: p.Credentials.PasswordFile,
+		password:     password,
+		pageSize:     p.PageSize,
+		workers:      p.Workers,
+		noPrompt:     true,
+	}
+	if conn.ca == "" {
+		conn.ca = os.Getenv("HTTPS_SERVER_KEY_PUBLIC")
+	}
+	if conn.user == "" {
+		conn.user = os.Getenv("HTTPS_CLIENT_USER")
+	}
+	if conn.passwordFile == "" && conn.password == "" {
+		conn.passwordFile = os.Getenv("HTTPS_CLIENT_PASSWORD_FILE")
+	}
+	return conn, nil
+}
+
+// Формат файла экспорта профиля: флаг команды, формат профиля или формат по умолчанию.
+//
+// Параметры:
+//
+// p - профиль сервера
+// format - формат из флага команды
+func fleetFormat(p config.Profile, format string) string {
+
+	switch {
+	case format != "":
+		return format
+	case p.Output.Format != "":
+		return p.Output.Format
+	}
+	return defaultFormat()
+}
+
+// Размещение файлов профиля: директория профиля или поддиректория базовой директории с именем профиля.
+//
+// Параметры:
+//
+// p - профиль сервера
+// base - базовые директория и шаблон имени
+func fleetOutput(p config.Profile, base libre.Output) libre.Output {
+
+	out := libre.Output{Dir: p.Output.Dir, Template: p.Output.Name}
+	if out.Dir == "" {
+		out.Dir = filepath.Join(base.Dir, p.Name)
+	}
+	if out.Template == "" {
+		out.Template = base.Template
+	}
+	return out
+}
+
+// Запрос состояния сервера и запись в файл status.json директории сервера.
+// Возвращается имя файла и ошибка.
+//
+// Параметры:
+//
+// ctx - контекст запроса
+// client - клиент сервера
+// dir - директория файлов сервера
+func writeStatus(ctx context.Context, client *clientapi.Client, dir string) (string, error) {
+
+	statusSrv, err := client.StatusCtx(ctx)
+	if err != nil {
+		return "", fmt.Errorf("ошибка при запросе состояния сервера: {%w}", err)
+	}
+
+	data, err := json.MarshalIndent(statusSrv, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("ошибка обработки состояния сервера: {%v}", err)
+	}
+
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return "", fmt.Errorf("%w: {%v}", errSaveFile, err)
+	}
+	fileName := filepath.Join(dir, "status.json")
+	err = os.WriteFile(fileName, append(data, '\n'), 0o644)
+	if err != nil {
+		return "", fmt.Errorf("%w: {%v}", errSaveFile, err)
+	}
+	return fileName, nil
+}
+
+// Запись отчёта команды fleet в файл JSON. Возвращается ошибка.
+//
+// Параметры:
+//
+// path - путь к файлу отчёта
+// rep - отчёт по объектам
+func writeReport(path string, rep fleet.Report) error {
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла отчёта: {%v}", err)
+	}
+
+	err = rep.WriteJSON(file)
+	errClose := file.Close()
+	if err == nil && errClose != nil {
+		err = fmt.Errorf("ошибка при закрытии файла отчёта: {%v}", errClose)
+	}
+	return err
+}
@@ -3,68 +3,245 @@ package main
 import (
 	clientapi "clienthttps/internal/client/clientAPI"
 	"clienthttps/internal/client/libre"
+	"context"
+	"errors"
 	"fmt"
-	"log"
-	"net/http"
+	"io"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
-	"github.com/joho/godotenv"
 	"golang.org/x/term"
 )
 
+// Крайний срок ожидания ответа сервера на один запрос
+const requestTimeout = 30 * time.Second
+
+// Границы адаптивного размера части
+var adaptivePaging = clientapi.AdaptivePaging{
+	MinSize: 50,
+	MaxSize: 5000,
+	Fast:    time.Second,
+}
+
 func main() {
-	var user clientapi.UserLogin
+	os.Exit(runCLI(os.Args[1:]))
+}
+
+// Опции выгрузки частей из переменных окружения. Возвращаются опции клиента и ошибка.
+//
+// HTTPS_CLIENT_WORKERS - количество параллельных запросов.
+// HTTPS_CLIENT_PAGE_SIZE - количество строк в одном запросе.
+// HTTPS_CLIENT_ADAPTIVE - адаптивный размер части (true/false).
+func queueOptions() ([]clientapi.Option, error) {
 
-	clientHttps, userInfo := prepare(&user)
+	opts := make([]clientapi.Option, 0)
 
-	run(clientHttps, userInfo)
+	if v := os.Getenv("HTTPS_CLIENT_WORKERS"); v != "" {
+		workers, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("значение HTTPS_CLIENT_WORKERS не является числом: {%s}", v)
+		}
+		opts = append(opts, clientapi.WithWorkers(workers))
+	}
+
+	if v := os.Getenv("HTTPS_CLIENT_PAGE_SIZE"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("значение HTTPS_CLIENT_PAGE_SIZE не является числом: {%s}", v)
+		}
+		opts = append(opts, clientapi.WithPageSize(size))
+	}
+
+	if v := os.Getenv("HTTPS_CLIENT_ADAPTIVE"); v != "" {
+		adaptive, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("значение HTTPS_CLIENT_ADAPTIVE не является true/false: {%s}", v)
+		}
+		if adaptive {
+			opts = append(opts, clientapi.WithAdaptivePaging(adaptivePaging))
+		}
+	}
+
+	return opts, nil
 }
 
-// Подготовительные действия. Возвращается указатель на http кдиент и данные пользователя.
+// Формат файла экспорта по умолчанию из переменной окружения HTTPS_CLIENT_EXPORT_FORMAT.
+func defaultFormat() string {
+	if v := os.Getenv("HTTPS_CLIENT_EXPORT_FORMAT"); v != "" {
+		return v
+	}
+	return libre.FormatXLSX
+}
+
+// Формат экспорта по имени. Параметры форматов задаются переменными окружения.
+// Возвращается формат экспорта и ошибка.
+//
+// HTTPS_CLIENT_CSV_BOM - метка порядка байтов UTF-8 в начале файла (true/false).
+// HTTPS_CLIENT_CSV_DECIMAL_SEP - десятичный разделитель числовых значений.
+// HTTPS_CLIENT_TEXT_CELLS - запись значений в книгу текстом, без разбора типов (true/false).
+// HTTPS_CLIENT_TIMEZONE - часовой пояс отображения меток времени в книге (например, Asia/Novosibirsk, Local).
+// HTTPS_CLIENT_TIME_FORMAT - числовой формат ячеек времени в книге xlsx.
+// HTTPS_CLIENT_PIVOT - лист широкого формата в книге xlsx и размещение качества (column, sheet, none).
+// HTTPS_CLIENT_PIVOT_TOLERANCE - допуск объединения меток времени в строку листа широкого формата.
+// HTTPS_CLIENT_PIVOT_INTERVAL - интервал передискретизации листа широкого формата.
+// HTTPS_CLIENT_SPLIT - разделение строк книги xlsx по листам устройств или переменных (device, tag).
+// HTTPS_CLIENT_CHART - графики значений в книге xlsx по переменным или устройствам (tag, device).
 //
 // Параметры:
 //
-// usr - данные пользователя
-func prepare(usr *clientapi.UserLogin) (client *http.Client, userInfo clientapi.UserLogin) {
+// format - имя формата
+func exporter(format string) (libre.Exporter, error) {
 
-	// Чтение переменных окружения
-	err := godotenv.Load("./configs/.env")
+	exp, err := libre.NewExporter(format)
 	if err != nil {
-		log.Fatalf("ошибка чтения переменных окружения: {%v}\n", err)
+		return nil, err
 	}
 
-	// Создание Https клиента
-	client, err = clientapi.CreateHttpsClient()
-	if err != nil {
-		log.Fatalf("ошибка создания https клиента: {%v}\n", err)
+	switch e := exp.(type) {
+	case libre.CSV:
+		return csvOptions(e)
+	case libre.XLSX:
+		e.Cells, err = cellFormat()
+		if err != nil {
+			return nil, err
+		}
+		e.Pivot, err = pivotOptions()
+		if err != nil {
+			return nil, err
+		}
+		switch v := os.Getenv("HTTPS_CLIENT_SPLIT"); v {
+		case "":
+		case "device":
+			e.Split = libre.SplitDevice
+		case "tag":
+			e.Split = libre.SplitTag
+		default:
+			return nil, fmt.Errorf("значение HTTPS_CLIENT_SPLIT не является device/tag: {%s}", v)
+		}
+		switch v := os.Getenv("HTTPS_CLIENT_CHART"); v {
+		case "":
+		case "tag":
+			e.Chart = libre.ChartTag
+		case "device":
+			e.Chart = libre.ChartDevice
+		default:
+			return nil, fmt.Errorf("значение HTTPS_CLIENT_CHART не является tag/device: {%s}", v)
+		}
+		return e, nil
+	case libre.ODS:
+		e.Cells, err = cellFormat()
+		return e, err
 	}
+	return exp, nil
+}
 
-	// Ввод данных пользователя при запуске приложения
-	err = typeUserData(usr)
-	if err != nil {
-		log.Fatalf("ошибка ввода данных при старте приложения: {%v}\n", err)
+// Параметры CSV и TSV из переменных окружения. Возвращается формат экспорта и ошибка.
+func csvOptions(csv libre.CSV) (libre.Exporter, error) {
+
+	var err error
+
+	if v := os.Getenv("HTTPS_CLIENT_CSV_BOM"); v != "" {
+		csv.BOM, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("значение HTTPS_CLIENT_CSV_BOM не является true/false: {%s}", v)
+		}
 	}
 
-	// Регистрация на сервере и получение токена
-	u := "https://" + os.Getenv("HTTPS_SERVER_IP") + ":" + os.Getenv("HTTPS_SERVER_PORT") + "/registration"
+	if v := os.Getenv("HTTPS_CLIENT_CSV_DECIMAL_SEP"); v != "" {
+		sep := []rune(v)
+		if len(sep) != 1 {
+			return nil, fmt.Errorf("значение HTTPS_CLIENT_CSV_DECIMAL_SEP не является одним символом: {%s}", v)
+		}
+		csv.DecimalSep = sep[0]
+	}
 
-	userInfo, err = clientapi.ReqLoginServer(usr.Name, usr.Password, u, client)
-	if err != nil {
-		log.Fatalf("Ошибка регистрации на сервере: {%v}\n", err)
+	return csv, nil
+}
+
+// Параметры листа широкого формата из переменных окружения. Возвращаются параметры (nil - без листа) и ошибка.
+func pivotOptions() (*libre.Pivot, error) {
+
+	var pivot libre.Pivot
+	var err error
+
+	switch v := os.Getenv("HTTPS_CLIENT_PIVOT"); v {
+	case "":
+		return nil, nil
+	case "column":
+		pivot.Quality = libre.QualityColumn
+	case "sheet":
+		pivot.Quality = libre.QualitySheet
+	case "none":
+		pivot.Quality = libre.QualityNone
+	default:
+		return nil, fmt.Errorf("значение HTTPS_CLIENT_PIVOT не является column/sheet/none: {%s}", v)
+	}
+
+	if v := os.Getenv("HTTPS_CLIENT_PIVOT_TOLERANCE"); v != "" {
+		pivot.Tolerance, err = time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("значение HTTPS_CLIENT_PIVOT_TOLERANCE не является интервалом времени: {%s}", v)
+		}
+	}
+
+	if v := os.Getenv("HTTPS_CLIENT_PIVOT_INTERVAL"); v != "" {
+		pivot.Interval, err = time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("значение HTTPS_CLIENT_PIVOT_INTERVAL не является интервалом времени: {%s}", v)
+		}
 	}
-	fmt.Println("Регистрация пользователя выполнена")
-	fmt.Println()
 
-	return client, userInfo
+	return &pivot, nil
 }
 
-// Вывод меню действия.
+// Размещение и имя файлов экспорта из переменных окружения.
+//
+// HTTPS_CLIENT_OUTPUT_DIR - директория файлов экспорта.
+// HTTPS_CLIENT_NAME_TEMPLATE - шаблон имени файла ({server}, {date}, {range}, {format}, {timestamp}).
+func output() libre.Output {
+	return libre.Output{
+		Dir:      os.Getenv("HTTPS_CLIENT_OUTPUT_DIR"),
+		Template: os.Getenv("HTTPS_CLIENT_NAME_TEMPLATE"),
+	}
+}
+
+// Параметры записи значений в ячейки книги из переменных окружения. Возвращаются параметры и ошибка.
+func cellFormat() (libre.CellFormat, error) {
+
+	var cf libre.CellFormat
+	var err error
+
+	if v := os.Getenv("HTTPS_CLIENT_TEXT_CELLS"); v != "" {
+		cf.Text, err = strconv.ParseBool(v)
+		if err != nil {
+			return cf, fmt.Errorf("значение HTTPS_CLIENT_TEXT_CELLS не является true/false: {%s}", v)
+		}
+	}
+
+	if v := os.Getenv("HTTPS_CLIENT_TIMEZONE"); v != "" {
+		cf.Location, err = time.LoadLocation(v)
+		if err != nil {
+			return cf, fmt.Errorf("неизвестный часовой пояс HTTPS_CLIENT_TIMEZONE: {%s}", v)
+		}
+	}
+
+	cf.TimeFormat = os.Getenv("HTTPS_CLIENT_TIME_FORMAT")
+
+	return cf, nil
+}
+
+// Вывод меню действия. Возвращается ошибка, прервавшая работу меню.
 //
 // Параметры:
 //
-// client - указатель на http клиента
-func run(client *http.Client, usr clientapi.UserLogin) {
+// client - указатель на клиента сервера
+// out - размещение файлов экспорта
+// exportFormat - формат файла экспорта по умолчанию
+func run(client *clientapi.Client, out libre.Output, exportFormat string) error {
 	var str string
 
 	for {
@@ -74,8 +251,14 @@ func run(client *http.Client, usr clientapi.UserLogin) {
 		fmt.Println("3: Завершение работы")
 		fmt.Print("Выбор действия-> ")
 		_, err := fmt.Scanln(&str)
+		if errors.Is(err, io.EOF) {
+			// Ввод закрыт - завершение работы
+			fmt.Println()
+			return nil
+		}
 		if err != nil {
-			log.Fatal("Ошибка ввода данных")
+			fmt.Println("Ошибка ввода данных")
+			continue
 		}
 		fmt.Println("---------------------------")
 
@@ -83,76 +266,73 @@ func run(client *http.Client, usr clientapi.UserLogin) {
 		case "1": // Вывод статусной информации сервера
 
 			// Запрос данных сервера
-			u := fmt.Sprintf("https://%s:%s/status", os.Getenv("HTTPS_SERVER_IP"), os.Getenv("HTTPS_SERVER_PORT"))
-
-			statusSrv, err := clientapi.ReqStatusServer(usr.Token, usr.Name, u, client)
+			statusSrv, err := client.Status()
 			if err != nil {
-				log.Fatalf("ошибка при запросе состояния сервера: {%v}\n", err)
+				// После исчерпания повторов возврат в меню: связь может восстановиться
+				fmt.Printf("Ошибка при запросе состояния сервера: {%v}\n", err)
+				continue
 			}
 
 			// Отображение принятых данных
 			err = showStatusServer(statusSrv)
 			if err != nil {
-				fmt.Println("Ошибка:", err)
 				fmt.Println("Работа прервана")
-				return
+				return err
 			}
 			continue
 
 		case "2": // Запрос архивных данных
-			var date string
+			var from, to string
 			fmt.Println()
-			fmt.Print("Введите дату экспорта (YYYY-MM-DD): ")
-			fmt.Scanln(&date)
-
-			// Запрос количества строк по дате
-			u := fmt.Sprintf("https://%s:%s/cntstr", os.Getenv("HTTPS_SERVER_IP"), os.Getenv("HTTPS_SERVER_PORT"))
-
-			cntStr, err := clientapi.ReqCntStrByDateDB(usr.Token, usr.Name, date, u, client)
-			if err != nil {
-				fmt.Println("Ошибка: ", err)
-				fmt.Println("Работа прервана")
-				return
+			fmt.Print("Введите начальную дату экспорта (YYYY-MM-DD): ")
+			fmt.Scanln(&from)
+			fmt.Print("Введите конечную дату экспорта (YYYY-MM-DD, Enter - только начальная): ")
+			fmt.Scanln(&to)
+			if to == "" {
+				to = from
 			}
-			fmt.Printf("По дате {%s} содержится {%d} строк\n", date, cntStr)
-
-			// Выполнение очереди запросов на получение строк
-			u = fmt.Sprintf("https://%s:%s/partdatadb", os.Getenv("HTTPS_SERVER_IP"), os.Getenv("HTTPS_SERVER_PORT"))
+			format := exportFormat
+			fmt.Printf("Введите формат файла (%s, Enter - %s): ", strings.Join(libre.Formats(), ", "), format)
+			fmt.Scanln(&format)
 
-			rxData, err := clientapi.QueReqPartDataDB(date, usr.Token, usr.Name, u, cntStr, client)
+			exp, err := exporter(format)
 			if err != nil {
 				fmt.Println("Ошибка: ", err)
-				fmt.Println("Работа прервана")
-				return
-			}
-
-			// Подготовка данных для сохранения
-			forSave := clientapi.RxDataDB{
-				StartDate: date,
-				Data:      make([]clientapi.DataEl, 0),
-			}
-			for _, v := range rxData {
-				forSave.Data = append(forSave.Data, v.Data...)
+				continue
 			}
 
-			// Формирование Exlx файла данных
-			fileName, err := libre.SaveDataXlsx(forSave)
-			if err != nil {
-				fmt.Printf("ошибка при сохранении данных в xlsx файл: {%v}", err)
+			// Запрос количества строк и очередь запросов на получение строк по каждому дню диапазона.
+			// Строки записываются в файл по мере приёма частей. Ctrl-C прерывает выгрузку с возвратом в меню.
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			fileName, cnt, err := exportRange(ctx, client, exp, out, from, to)
+			stop()
+
+			var errCancel *clientapi.CanceledError
+			switch {
+			case errors.As(err, &errCancel):
+				fmt.Printf("Выгрузка прервана. Принято частей {%d} из {%d}\n", errCancel.Received, errCancel.Total)
+				continue
+			case errors.Is(err, errSaveFile):
 				fmt.Println("Работа прервана")
-				return
+				return err
+			case err != nil:
+				// После исчерпания повторов возврат в меню: связь может восстановиться
+				fmt.Println("Ошибка: ", err)
+				fmt.Println("Выгрузка не выполнена")
+				continue
 			}
 
+			fmt.Printf("Записано строк {%d}\n", cnt)
 			fmt.Printf("Задача выполнена. Создан файл - %s", fileName)
 			fmt.Println()
 			continue
 
 		case "3": // Завершение работы
-			return
+			return nil
 
 		default: // Ошибка ввода пользователя
 			fmt.Println("Ошибка ввода. Работа завершена")
-			return
+			return nil
 		}
 	}
 
@@ -194,7 +374,7 @@ func showStatusServer(statusSrv clientapi.RxStatusSrv) error {
 	return nil
 }
 
-// Ввод данных пользователя при запуске приложения.
+// Ввод в терминале незаданных имени и пароля пользователя.
 //
 // Параметры:
 //
@@ -204,20 +384,24 @@ func typeUserData(usr *clientapi.UserLogin) error {
 	fd := int(syscall.Stdin)
 
 	fmt.Println("Необходима регистрация на сервере.")
-	fmt.Print("Имя пользователя: ")
-	data, err := term.ReadPassword(fd)
-	if err != nil {
-		return fmt.Errorf("ошибка при чтении имени: {%v}", err)
+	if usr.Name == "" {
+		fmt.Print("Имя пользователя: ")
+		data, err := term.ReadPassword(fd)
+		if err != nil {
+			return fmt.Errorf("ошибка при чтении имени: {%v}", err)
+		}
+		usr.Name = string(data)
+		fmt.Println()
 	}
-	usr.Name = string(data)
-	fmt.Println()
 
-	fmt.Print("Пароль пользователя: ")
-	data, err = term.ReadPassword(fd)
-	if err != nil {
-		return fmt.Errorf("ошибка при чтении пароля: {%v}", err)
+	if usr.Password == "" {
+		fmt.Print("Пароль пользователя: ")
+		data, err := term.ReadPassword(fd)
+		if err != nil {
+			return fmt.Errorf("ошибка при чтении пароля: {%v}", err)
+		}
+		usr.Password = string(data)
 	}
-	usr.Password = string(data)
 
 	fmt.Println()
 	fmt.Println()
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"clienthttps/internal/client/config"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// Файл настроек с профилями серверов по умолчанию
+const defaultConfigFile = "./configs/config.yaml"
+
+// Чтение файла настроек HTTPS_CLIENT_CONFIG (по умолчанию ./configs/config.yaml). Возвращается указатель
+// на настройки (nil - файла по умолчанию нет) и ошибка.
+func loadConfig() (*config.Config, error) {
+
+	path := os.Getenv("HTTPS_CLIENT_CONFIG")
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigFile
+	}
+
+	if _, err := os.Stat(path); !explicit && errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, &usageError{err: err}
+	}
+	return cfg, nil
+}
+
+// Выбор профиля сервера из файла настроек. Без файла настроек, выбранного профиля и профиля по умолчанию
+// возвращается пустой профиль. Возвращается профиль и ошибка.
+//
+// Параметры:
+//
+// name - имя профиля (пусто - профиль по умолчанию)
+func selectProfile(name string) (config.Profile, error) {
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return config.Profile{}, err
+	}
+	if cfg == nil {
+		if name != "" {
+			return config.Profile{}, usagef("профиль {%s} не найден: нет файла настроек {%s}", name, defaultConfigFile)
+		}
+		return config.Profile{}, nil
+	}
+	if name == "" && cfg.Default == "" {
+		return config.Profile{}, nil
+	}
+
+	p, err := cfg.Profile(name)
+	if err != nil {
+		return config.Profile{}, &usageError{err: err}
+	}
+	return p, nil
+}
+
+// Применение профиля сервера к незаданным в командной строке флагам подключения. Значения профиля
+// имеют приоритет перед переменными окружения, флаги команды - перед профилем. Окружение процесса
+// не меняется. Возвращается выбранный профиль (пустой - профиль не применялся) и ошибка.
+//
+// Параметры:
+//
+// flags - разобранный набор флагов команды
+func (c *connFlags) resolve(flags *flag.FlagSet) (config.Profile, error) {
+
+	p, err := selectProfile(c.profile)
+	if err != nil {
+		return p, err
+	}
+
+	fromProfile(flags, "host", &c.host, p.Host)
+	fromProfile(flags, "port", &c.port, p.Port)
+	fromProfile(flags, "ca", &c.ca, p.CA)
+	fromProfile(flags, "user", &c.user, p.Credentials.User)
+
+	// Источник пароля профиля заменяет источник из .env
+	if !isSet(flags, "password-file") {
+		switch {
+		case p.Credentials.PasswordFile != "":
+			c.passwordFile = p.Credentials.PasswordFile
+		case p.Credentials.PasswordEnv != "":
+			c.password, err = p.Credentials.Password()
+			if err != nil {
+				return p, &usageError{err: fmt.Errorf("профиль {%s}: %w", p.Name, err)}
+			}
+			c.passwordFile = ""
+		}
+	}
+
+	c.pageSize = p.PageSize
+	c.workers = p.Workers
+	return p, nil
+}
+
+// Значение профиля для флага, не заданного в командной строке. Пустое значение профиля не применяется.
+//
+// Параметры:
+//
+// flags - разобранный набор флагов команды
+// name - имя флага
+// dst - значение флага
+// value - значение профиля
+func fromProfile(flags *flag.FlagSet, name string, dst *string, value string) {
+	if value != "" && !isSet(flags, name) {
+		*dst = value
+	}
+}
+
+// Признак флага, заданного в командной строке.
+//
+// Параметры:
+//
+// flags - разобранный набор флагов команды
+// name - имя флага
+func isSet(flags *flag.FlagSet, name string) bool {
+
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"clienthttps/internal/client/archive"
+	clientapi "clienthttps/internal/client/clientAPI"
+	"clienthttps/internal/client/config"
+	"clienthttps/internal/client/fleet"
+	"clienthttps/internal/client/libre"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSyncInterval = time.Minute  // период опроса серверов по умолчанию
+	defaultSyncDays     = 30           // глубина архива в днях по умолчанию
+	defaultArchiveDir   = "./archive"  // директория архива по умолчанию
+	dateLayout          = "2006-01-02" // формат даты аргументов команды
+)
+
+// Сервер, архив которого загружается командой sync
+type syncTarget struct {
+	name     string    // имя профиля или адрес сервера
+	conn     connFlags // параметры подключения
+	dir      string    // директория архива сервера
+	format   string    // формат файлов дней
+	reported string    // последнее выведенное в журнал состояние сервера
+}
+
+// Команда sync - опрос серверов и загрузка в локальный архив дней, которых в нём ещё нет.
+// Сервер опрашивается запросом состояния без регистрации; как только сервер отвечает, выполняется
+// регистрация и загрузка недостающих дней. Прерванная обрывом связи загрузка продолжается при следующем
+// опросе с прерванного дня. С флагом -once выполняется один опрос с отчётом по объектам.
+func cmdSync(args []string) int {
+
+	var names, dir, format, since, until string
+	var interval, timeout time.Duration
+	var once bool
+
+	dirDefault := os.Getenv("HTTPS_CLIENT_ARCHIVE_DIR")
+	if dirDefault == "" {
+		dirDefault = defaultArchiveDir
+	}
+
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	flags.StringVar(&names, "profiles", "", "имена профилей через запятую (пусто - все профили файла настроек или сервер из переменных окружения)")
+	flags.StringVar(&dir, "dir", dirDefault, "директория архива (HTTPS_CLIENT_ARCHIVE_DIR), для профилей - поддиректория с именем профиля")
+	flags.StringVar(&format, "format", "", "формат файлов дней ("+strings.Join(libre.Formats(), ", ")+"; по умолчанию - формат профиля)")
+	flags.StringVar(&since, "since", time.Now().AddDate(0, 0, -defaultSyncDays).Format(dateLayout), "первый день архива (YYYY-MM-DD)")
+	flags.StringVar(&until, "until", "", "последний день архива (YYYY-MM-DD, по умолчанию - вчерашний день)")
+	flags.DurationVar(&interval, "interval", defaultSyncInterval, "период опроса серверов")
+	flags.BoolVar(&once, "once", false, "один опрос серверов с отчётом по объектам")
+	flags.DurationVar(&timeout, "timeout", 0, "ограничение времени выполнения команды (0 - без ограничения)")
+	if ok, code := parseFlags(flags, args); !ok {
+		return code
+	}
+	if interval <= 0 {
+		return fail(usagef("период опроса серверов должен быть больше нуля: {%s}", interval))
+	}
+	first, err := time.ParseInLocation(dateLayout, since, time.Local)
+	if err != nil {
+		return fail(usagef("неверный формат первого дня архива: {%s}", since))
+	}
+	var last time.Time
+	if until != "" {
+		last, err = time.ParseInLocation(dateLayout, until, time.Local)
+		if err != nil {
+			return fail(usagef("неверный формат последнего дня архива: {%s}", until))
+		}
+	}
+
+	targets, err := syncTargets(names, dir, format)
+	if err != nil {
+		return fail(err)
+	}
+
+	list := make([]string, 0, len(targets))
+	byName := make(map[string]*syncTarget, len(targets))
+	for i := range targets {
+		list = append(list, targets[i].name)
+		byName[targets[i].name] = &targets[i]
+	}
+
+	ctx, stop := commandCtx(timeout)
+	defer stop()
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+
+	// Один проход по серверу: проверка доступности, регистрация и загрузка недостающих дней
+	pass := func(ctx context.Context, t *syncTarget, client *clientapi.Client) (archive.Result, error) {
+
+		err := client.Ping(ctx)
+		if err != nil {
+			return archive.Result{}, err
+		}
+		t.report(logger, "сервер доступен")
+
+		if client.User().Token == "" {
+			err = login(ctx, client, &t.conn)
+			if err != nil {
+				return archive.Result{}, err
+			}
+		}
+
+		// Время запуска сервера для сводки файлов
+		var timeStart string
+		if statusSrv, err := client.StatusCtx(ctx); err == nil {
+			timeStart = statusSrv.TimeStart
+		}
+
+		exp, err := exporter(t.format)
+		if err != nil {
+			return archive.Result{}, err
+		}
+
+		s := archive.Syncer{
+			Dir:       t.dir,
+			Exporter:  exp,
+			Server:    client.BaseURL(),
+			TimeStart: timeStart,
+			Since:     first,
+			Until:     last,
+			OnDay: func(date string, day archive.Day) {
+				if day.File == "" {
+					logger.Printf("%s: загружен день {%s}, строк нет", t.name, date)
+					return
+				}
+				logger.Printf("%s: загружен день {%s}, строк {%d}, файл {%s}", t.name, date, day.Rows, day.File)
+			},
+		}
+		if s.Until.IsZero() {
+			s.Until = time.Now().AddDate(0, 0, -1)
+		}
+		return s.Sync(ctx, client)
+	}
+
+	job := func(ctx context.Context, name string) (string, error) {
+
+		t := byName[name]
+		client, err := newClient(&t.conn, false)
+		if err != nil {
+			return "", err
+		}
+
+		for {
+			res, err := pass(ctx, t, client)
+			var usage *usageError
+			switch {
+			case errors.As(err, &usage):
+				// Ошибку настроек повторный опрос не исправит
+				return "", err
+			case err != nil && ctx.Err() == nil:
+				t.report(logger, err.Error())
+			case err == nil && res.Days > 0:
+				logger.Printf("%s: архив загружен, дней {%d}, строк {%d}", t.name, res.Days, res.Rows)
+			}
+
+			if once {
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("дней %d, строк %d", res.Days, res.Rows), nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return "", nil
+			case <-time.After(interval):
+			}
+		}
+	}
+
+	runner := fleet.Runner{Workers: len(list)}
+	if !once {
+		runner.OnDone = func(res fleet.Result) {
+			if res.Err != nil {
+				logger.Printf("%s: опрос сервера остановлен: %v", res.Target, res.Err)
+			}
+		}
+		logger.Printf("Опрос серверов {%d} с периодом {%s}", len(list), interval)
+	}
+	rep := runner.Run(ctx, list, job)
+
+	if !once {
+		// Работа до сигнала остановки - штатное завершение
+		logger.Println("Опрос серверов остановлен")
+		return exitOK
+	}
+
+	err = rep.WriteText(os.Stdout)
+	if err != nil {
+		return failCtx(ctx, err)
+	}
+	switch {
+	case ctx.Err() != nil:
+		return exitInterrupted
+	case !rep.OK():
+		return exitPartial
+	}
+	return exitOK
+}
+
+// Вывод в журнал состояния сервера только при его изменении.
+//
+// Параметры:
+//
+// logger - журнал команды
+// state - состояние сервера
+func (t *syncTarget) report(logger *log.Logger, state string) {
+
+	if t.reported == state {
+		return
+	}
+	t.reported = state
+	logger.Printf("%s: %s", t.name, state)
+}
+
+// Серверы команды sync: профили файла настроек или сервер из переменных окружения, если файла нет.
+// Форматы файлов проверяются до обращения к серверам. Возвращается список серверов и ошибка.
+//
+// Параметры:
+//
+// names - имена профилей через запятую
+// dir - директория архива
+// format - формат из флага команды
+func syncTargets(names, dir, format string) ([]syncTarget, error) {
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []syncTarget
+	switch {
+	case cfg != nil:
+		list := cfg.Names()
+		if names != "" {
+			list = strings.Split(names, ",")
+		}
+		for _, name := range list {
+			p, err := cfg.Profile(strings.TrimSpace(name))
+			if err != nil {
+				return nil, &usageError{err: err}
+			}
+			conn, err := profileConn(p)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, syncTarget{
+				name:   p.Name,
+				conn:   conn,
+				dir:    filepath.Join(dir, p.Name),
+				format: fleetFormat(p, format),
+			})
+		}
+
+	case names != "":
+		return nil, usagef("нет файла настроек с профилями серверов {%s}", defaultConfigFile)
+
+	default:
+		p := config.Profile{Host: os.Getenv("HTTPS_SERVER_IP"), Port: os.Getenv("HTTPS_SERVER_PORT")}
+		conn, err := profileConn(p)
+		if err != nil {
+			return nil, err
+		}
+		if conn.host == "" || conn.port == "" {
+			return nil, usagef("не задан адрес сервера: задайте профили в файле настроек или HTTPS_SERVER_IP и HTTPS_SERVER_PORT")
+		}
+		targets = append(targets, syncTarget{
+			name:   conn.baseURL(),
+			conn:   conn,
+			dir:    dir,
+			format: fleetFormat(p, format),
+		})
+	}
+
+	for _, t := range targets {
+		if _, err := exporter(t.format); err != nil {
+			return nil, &usageError{err: fmt.Errorf("сервер {%s}: %w", t.name, err)}
+		}
+	}
+	return targets, nil
+}
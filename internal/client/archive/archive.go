@@ -0,0 +1,255 @@
+package archive
+
+import (
+	clientapi "clienthttps/internal/client/clientAPI"
+	"clienthttps/internal/client/libre"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Файл состояния локального архива сервера
+const stateFile = "sync-state.json"
+
+// Формат даты архивных данных
+const dateLayout = "2006-01-02"
+
+type (
+	// Загруженный день архива
+	Day struct {
+		Rows   int       `json:"rows"`           // количество строк
+		File   string    `json:"file,omitempty"` // файл дня (пусто - за день нет строк)
+		Synced time.Time `json:"synced"`         // время загрузки
+	}
+
+	// Состояние локального архива сервера: загруженные дни по дате
+	State struct {
+		Days map[string]Day `json:"days"`
+
+		path string // путь к файлу состояния
+	}
+
+	// Источник архивных данных сервера
+	Source interface {
+		// Строки диапазона дат по мере приёма частей с количеством строк по ответам /cntstr
+		StreamRangeCount(ctx context.Context, from, to string, cntStr *int) iter.Seq2[clientapi.DataEl, error]
+	}
+
+	// Загрузка в локальный архив дней, которых в нём ещё нет
+	Syncer struct {
+		Dir       string                     // директория архива сервера
+		Exporter  libre.Exporter             // формат файлов дней
+		Server    string                     // адрес сервера для сводки файла
+		TimeStart string                     // время запуска сервера для сводки файла
+		Since     time.Time                  // первый день архива
+		Until     time.Time                  // последний день архива включительно
+		OnDay     func(date string, day Day) // вызывается после загрузки каждого дня
+	}
+
+	// Итог загрузки
+	Result struct {
+		Days    int // загружено дней
+		Rows    int // загружено строк
+		Pending int // осталось дней для загрузки
+	}
+)
+
+// Чтение состояния архива из директории. Отсутствие файла состояния означает пустой архив.
+// Возвращается указатель на состояние и ошибка.
+//
+// Параметры:
+//
+// dir - директория архива сервера
+func LoadState(dir string) (*State, error) {
+
+	st := &State{Days: make(map[string]Day), path: filepath.Join(dir, stateFile)}
+
+	data, err := os.ReadFile(st.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("archive -> ошибка чтения состояния архива: {%v}", err)
+	}
+
+	err = json.Unmarshal(data, st)
+	if err != nil {
+		return nil, fmt.Errorf("archive -> ошибка обработки состояния архива {%s}: {%v}", st.path, err)
+	}
+	if st.Days == nil {
+		st.Days = make(map[string]Day)
+	}
+	return st, nil
+}
+
+// Признак загруженного дня.
+//
+// Параметры:
+//
+// date - дата в формате YYYY-MM-DD
+func (st *State) Done(date string) bool {
+	_, ok := st.Days[date]
+	return ok
+}
+
+// Отметка загруженного дня и запись состояния через временный файл. Возвращается ошибка.
+//
+// Параметры:
+//
+// date - дата в формате YYYY-MM-DD
+// day - загруженный день
+func (st *State) Mark(date string, day Day) error {
+
+	st.Days[date] = day
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("archive -> ошибка записи состояния архива: {%v}", err)
+	}
+
+	tmp := st.path + ".tmp"
+	err = os.WriteFile(tmp, append(data, '\n'), 0o644)
+	if err == nil {
+		err = os.Rename(tmp, st.path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		delete(st.Days, date)
+		return fmt.Errorf("archive -> ошибка записи состояния архива: {%v}", err)
+	}
+	return nil
+}
+
+// Дни диапазона Since..Until, которых нет в архиве, по возрастанию даты.
+//
+// Параметры:
+//
+// st - состояние архива
+func (s *Syncer) Pending(st *State) []string {
+
+	days := make([]string, 0)
+	until := s.Until.Format(dateLayout)
+	for d := s.Since; d.Format(dateLayout) <= until; d = d.AddDate(0, 0, 1) {
+		date := d.Format(dateLayout)
+		if !st.Done(date) {
+			days = append(days, date)
+		}
+	}
+	return days
+}
+
+// Загрузка недостающих дней по возрастанию даты. Каждый день записывается в файл через временный файл
+// и отмечается в состоянии архива только после полной записи, поэтому при обрыве связи загрузка
+// продолжается со следующего вызова с прерванного дня. Возвращается итог загрузки и ошибка первого
+// незагруженного дня.
+//
+// Параметры:
+//
+// ctx - контекст загрузки
+// src - источник архивных данных сервера
+func (s *Syncer) Sync(ctx context.Context, src Source) (Result, error) {
+
+	var res Result
+
+	if s.Exporter == nil {
+		return res, errors.New("archive -> не задан формат файлов архива")
+	}
+	if s.Since.IsZero() {
+		return res, errors.New("archive -> не задан первый день архива")
+	}
+
+	err := os.MkdirAll(s.Dir, 0o755)
+	if err != nil {
+		return res, fmt.Errorf("archive -> ошибка при создании директории архива: {%v}", err)
+	}
+
+	st, err := LoadState(s.Dir)
+	if err != nil {
+		return res, err
+	}
+
+	pending := s.Pending(st)
+	// Повторная загрузка дня заменяет его файл
+	out := libre.Output{Dir: s.Dir, Template: "{date}", Overwrite: true}
+
+	for i, date := range pending {
+		res.Pending = len(pending) - i
+
+		day, err := s.syncDay(ctx, src, out, date)
+		if err != nil {
+			return res, fmt.Errorf("archive -> день {%s}: %w", date, err)
+		}
+
+		err = st.Mark(date, day)
+		if err != nil {
+			return res, err
+		}
+
+		res.Days++
+		res.Rows += day.Rows
+		if s.OnDay != nil {
+			s.OnDay(date, day)
+		}
+	}
+
+	res.Pending = 0
+	return res, nil
+}
+
+// Загрузка одного дня: количество строк и строки принимаются одним потоком. Файл создаётся только
+// для дня со строками. Возвращается загруженный день и ошибка.
+//
+// Параметры:
+//
+// ctx - контекст загрузки
+// src - источник архивных данных сервера
+// out - размещение файлов дней
+// date - дата в формате YYYY-MM-DD
+func (s *Syncer) syncDay(ctx context.Context, src Source, out libre.Output, date string) (Day, error) {
+
+	var cnt int
+	next, stop := iter.Pull2(src.StreamRangeCount(ctx, date, date, &cnt))
+	defer stop()
+
+	// Первая строка дня: без строк файл не создаётся
+	first, errFirst, ok := next()
+	if !ok {
+		return Day{Synced: time.Now()}, nil
+	}
+	if errFirst != nil {
+		return Day{}, errFirst
+	}
+
+	rows := func(yield func(clientapi.DataEl, error) bool) {
+		if !yield(first, nil) {
+			return
+		}
+		for {
+			row, err, ok := next()
+			if !ok || !yield(row, err) {
+				return
+			}
+		}
+	}
+
+	ds := libre.Dataset{
+		StartDate:  date,
+		Rows:       rows,
+		Server:     s.Server,
+		ExportDate: time.Now(),
+		TimeStart:  s.TimeStart,
+		Expected:   &cnt,
+	}
+
+	fileName, n, err := out.Save(s.Exporter, ds)
+	if err != nil {
+		return Day{}, err
+	}
+	return Day{Rows: n, File: filepath.Base(fileName), Synced: time.Now()}, nil
+}
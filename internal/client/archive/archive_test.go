@@ -0,0 +1,147 @@
+package archive
+
+import (
+	clientapi "clienthttps/internal/client/clientAPI"
+	"clienthttps/internal/client/libre"
+	"context"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Имитация сервера с обрывом связи на заданной строке
+type fakeSource struct {
+	days   map[string][]clientapi.DataEl
+	dropAt map[string]int // день и номер строки обрыва связи
+	counts map[string]int // количество запросов дня
+}
+
+func (f *fakeSource) StreamRangeCount(ctx context.Context, from, to string, cntStr *int) iter.Seq2[clientapi.DataEl, error] {
+	f.counts[from]++
+	return func(yield func(clientapi.DataEl, error) bool) {
+		*cntStr = 0
+		for i, row := range f.days[from] {
+			if n, ok := f.dropAt[from]; ok && i == n {
+				delete(f.dropAt, from)
+				yield(clientapi.DataEl{}, fmt.Errorf("req-partdatadb -> %w", clientapi.ErrNetwork))
+				return
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
+		*cntStr = len(f.days[from])
+	}
+}
+
+// Имитация архивных данных дня
+func simDay(date string, n int) []clientapi.DataEl {
+	rows := make([]clientapi.DataEl, 0, n)
+	for i := 0; i < n; i++ {
+		rows = append(rows, clientapi.DataEl{Name: "Dev1.HR.Tag", Value: fmt.Sprint(i), Qual: "1", TimeStamp: date + "T03:01:02+07:00"})
+	}
+	return rows
+}
+
+// Загрузка архива - обрыв связи и продолжение с прерванного дня
+func Test_Syncer_Resume(t *testing.T) {
+
+	src := &fakeSource{
+		days: map[string][]clientapi.DataEl{
+			"2025-05-17": simDay("2025-05-17", 10),
+			"2025-05-19": simDay("2025-05-19", 20),
+			"2025-05-20": simDay("2025-05-20", 5),
+		},
+		dropAt: map[string]int{"2025-05-19": 7},
+		counts: make(map[string]int),
+	}
+
+	dir := t.TempDir()
+	var loaded []string
+	s := Syncer{
+		Dir:      dir,
+		Exporter: libre.JSON{NDJSON: true},
+		Since:    time.Date(2025, 5, 17, 0, 0, 0, 0, time.Local),
+		Until:    time.Date(2025, 5, 20, 0, 0, 0, 0, time.Local),
+		OnDay:    func(date string, day Day) { loaded = append(loaded, date) },
+	}
+
+	// Обрыв связи на третьем дне
+	res, err := s.Sync(context.Background(), src)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, clientapi.ErrNetwork)
+	assert.Contains(t, err.Error(), "archive -> день {2025-05-19}")
+	assert.Equal(t, Result{Days: 2, Rows: 10, Pending: 2}, res)
+	assert.Equal(t, []string{"2025-05-17", "2025-05-18"}, loaded)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"2025-05-17.ndjson", stateFile}, names, "недописанный файл дня не остаётся")
+
+	st, err := LoadState(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 0, st.Days["2025-05-18"].Rows, "день без строк отмечается без файла")
+	assert.Empty(t, st.Days["2025-05-18"].File)
+	assert.False(t, st.Done("2025-05-19"))
+
+	// Продолжение с прерванного дня, загруженные дни повторно не запрашиваются
+	res, err = s.Sync(context.Background(), src)
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, Result{Days: 2, Rows: 25}, res)
+	assert.Equal(t, map[string]int{"2025-05-17": 1, "2025-05-18": 1, "2025-05-19": 2, "2025-05-20": 1}, src.counts,
+		"один запрос на каждую попытку загрузки дня")
+
+	data, err := os.ReadFile(filepath.Join(dir, "2025-05-19.ndjson"))
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(data)), "\n"), 20)
+
+	// Архив полный
+	res, err = s.Sync(context.Background(), src)
+	require.NoError(t, err)
+	assert.Equal(t, Result{}, res)
+
+	st, err = LoadState(dir)
+	require.NoError(t, err)
+	assert.Empty(t, s.Pending(st))
+}
+
+// Дни для загрузки
+func Test_Syncer_Pending(t *testing.T) {
+
+	st := &State{Days: map[string]Day{"2025-02-28": {Rows: 1}}}
+
+	s := Syncer{
+		Since: time.Date(2025, 2, 27, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, []string{"2025-02-27", "2025-03-01", "2025-03-02"}, s.Pending(st))
+
+	s.Until = s.Since.AddDate(0, 0, -1)
+	assert.Empty(t, s.Pending(st), "первый день позже последнего")
+
+	_, err := (&Syncer{Dir: t.TempDir(), Exporter: libre.NewCSV()}).Sync(context.Background(), &fakeSource{})
+	require.Error(t, err)
+	assert.Equal(t, "archive -> не задан первый день архива", err.Error())
+}
+
+// Состояние архива - повреждённый файл
+func Test_LoadState_Error(t *testing.T) {
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, stateFile), []byte("{days"), 0o644))
+
+	_, err := LoadState(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "archive -> ошибка обработки состояния архива")
+}
@@ -0,0 +1,120 @@
+package clientapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Получение имени и пароля пользователя для повторной регистрации на сервере
+type CredentialsFunc func(ctx context.Context) (name, password string, err error)
+
+// Опция. Источник имени и пароля для регистрации, когда сессии ещё нет или сервер отклонил токен.
+// Без опции используются данные последнего успешного вызова Login.
+//
+// Параметры:
+//
+// fn - функция получения имени и пароля.
+func WithCredentials(fn CredentialsFunc) Option {
+	return func(c *Client) error {
+		if fn == nil {
+			return errors.New("client -> нет функции получения данных пользователя")
+		}
+		c.credsFn = fn
+		return nil
+	}
+}
+
+// Имя и пароль для повторной регистрации.
+func (c *Client) credentials(ctx context.Context) (name, password string, err error) {
+
+	if c.credsFn != nil {
+		return c.credsFn(ctx)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.user.Name == "" || c.password == "" {
+		return "", "", withKind(ErrNoSession, errors.New("client -> нет данных пользователя для повторной регистрации"))
+	}
+	return c.user.Name, c.password, nil
+}
+
+// Повторная регистрация на сервере после отказа в токене. Возвращаются данные новой сессии и ошибка.
+// Одновременные вызовы выполняют одну регистрацию: если токен уже обновлён другим запросом,
+// возвращается текущая сессия.
+//
+// Параметры:
+//
+// ctx - контекст запроса.
+// stale - отклонённый сервером токен.
+func (c *Client) relogin(ctx context.Context, stale string) (UserLogin, error) {
+
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	if usr := c.User(); usr.Token != "" && usr.Token != stale {
+		return usr, nil
+	}
+
+	name, password, err := c.credentials(ctx)
+	if err != nil {
+		return UserLogin{}, err
+	}
+
+	err = c.LoginCtx(ctx, name, password)
+	if err != nil {
+		return UserLogin{}, fmt.Errorf("client -> ошибка повторной регистрации: %w", err)
+	}
+
+	return c.User(), nil
+}
+
+// Выполнение запроса от имени пользователя. При отказе сервера в токене (401/403) выполняется
+// повторная регистрация и однократный повтор запроса. Возвращается ошибка.
+//
+// Параметры:
+//
+// ctx - контекст запроса.
+// fn - запрос к серверу с данными сессии.
+func (c *Client) authCall(ctx context.Context, fn func(ctx context.Context, usr UserLogin) error) error {
+	return c.authCallRetry(ctx, c.retry, fn)
+}
+
+// Выполнение запроса от имени пользователя с повторами по заданной политике. Возвращается ошибка.
+//
+// Параметры:
+//
+// ctx - контекст запроса.
+// retry - политика повторов.
+// fn - запрос к серверу с данными сессии.
+func (c *Client) authCallRetry(ctx context.Context, retry RetryPolicy, fn func(ctx context.Context, usr UserLogin) error) error {
+
+	usr, err := c.session()
+	if err != nil {
+		if c.credsFn == nil {
+			return err
+		}
+		usr, err = c.relogin(ctx, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.callRetry(ctx, retry, func(ctx context.Context) error {
+		return fn(ctx, usr)
+	})
+	if !errors.Is(err, ErrUnauthorized) {
+		return err
+	}
+
+	usr, errLogin := c.relogin(ctx, usr.Token)
+	if errLogin != nil {
+		return fmt.Errorf("%w, исходная ошибка: {%w}", errLogin, err)
+	}
+
+	return c.callRetry(ctx, retry, func(ctx context.Context) error {
+		return fn(ctx, usr)
+	})
+}
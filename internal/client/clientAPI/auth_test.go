@@ -0,0 +1,89 @@
+package clientapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Повторная регистрация при смене токена во время параллельной выгрузки
+func Test_Client_ReloginParallel(t *testing.T) {
+
+	date := "2025-05-18"
+	rows := simRows(3000)
+	fs := newFakeServer(t, map[string][]DataEl{date: rows})
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()), WithWorkers(8))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	// Смена токена после пятого запроса части
+	fs.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == pathPartDataDB && fs.count(pathPartDataDB) == 5 {
+			fs.rotateToken("new-token")
+		}
+		return false
+	})
+
+	result, err := client.FetchDay(date)
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, rows, result.DataDB().Data)
+	assert.Equal(t, "new-token", client.User().Token)
+	assert.Equal(t, 2, fs.count(pathLogin), "одновременные отказы должны приводить к одной повторной регистрации")
+}
+
+// Регистрация по функции получения данных пользователя
+func Test_Client_Credentials(t *testing.T) {
+
+	fs := newFakeServer(t, nil)
+
+	var calls atomic.Int32
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()), WithCredentials(func(ctx context.Context) (string, string, error) {
+		calls.Add(1)
+		return fs.name, fs.password, nil
+	}))
+	require.NoError(t, err)
+
+	// Регистрация выполняется при первом запросе
+	_, err = client.Status()
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Повторная регистрация после смены токена
+	fs.rotateToken("new-token")
+	_, err = client.Status()
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, 2, fs.count(pathLogin))
+}
+
+// Повторная регистрация - неуспешность
+func Test_Client_ReloginError(t *testing.T) {
+
+	fs := newFakeServer(t, nil)
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	// Пароль изменён на сервере, токен отклоняется
+	fs.mu.Lock()
+	fs.password = "changed"
+	fs.mu.Unlock()
+	fs.rotateToken("new-token")
+
+	_, err = client.Status()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	var errHTTP *HTTPError
+	require.True(t, errors.As(err, &errHTTP))
+	assert.Equal(t, pathLogin, errHTTP.Endpoint, "первой в цепочке находится ошибка повторной регистрации")
+	assert.Equal(t, 2, fs.count(pathLogin))
+	assert.Equal(t, 1, fs.count(pathStatus), "запрос не повторяется после неуспешной регистрации")
+}
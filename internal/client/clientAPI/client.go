@@ -0,0 +1,514 @@
+package clientapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Пути запросов к серверу
+const (
+	pathLogin      = "/registration"
+	pathStatus     = "/status"
+	pathCntStr     = "/cntstr"
+	pathPartDataDB = "/partdatadb"
+)
+
+type (
+	// Клиент сервера BlackBox. Создаётся один раз и хранит адрес сервера, https клиент и сессию пользователя.
+	Client struct {
+		baseURL string
+		http    *http.Client
+		timeout time.Duration
+		retry   RetryPolicy
+		queue   queueCfg
+
+		mu       sync.RWMutex
+		user     UserLogin
+		password string          // пароль последней успешной регистрации
+		credsFn  CredentialsFunc // источник данных пользователя для повторной регистрации
+		loginMu  sync.Mutex      // одна повторная регистрация для одновременных запросов
+	}
+
+	// Опция клиента
+	Option func(c *Client) error
+
+	// Результат выгрузки архивных данных за день
+	DownloadResult struct {
+		Date      string
+		CntStr    int
+		Pages     []PartDataDB
+		PageSizes []int // размеры запрошенных частей по порядку
+	}
+)
+
+// Создание клиента сервера. Возвращается указатель на клиента и ошибка.
+//
+// Параметры:
+//
+// baseURL - адрес сервера вида https://host:port.
+// opts - опции клиента.
+func NewClient(baseURL string, opts ...Option) (*Client, error) {
+
+	// Проверка аргументов
+	if baseURL == "" {
+		return nil, withKind(ErrInvalidArg, errors.New("client -> пустое значение адреса сервера"))
+	}
+	parseU, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, withKind(ErrInvalidArg, fmt.Errorf("client -> ошибка парсинга адреса сервера: {%w}", err))
+	}
+	if parseU.Scheme != "http" && parseU.Scheme != "https" {
+		return nil, withKind(ErrInvalidArg, fmt.Errorf("client -> неподдерживаемая схема адреса сервера: {%s}", parseU.Scheme))
+	}
+	if parseU.Host == "" {
+		return nil, withKind(ErrInvalidArg, errors.New("client -> в адресе сервера нет хоста"))
+	}
+
+	c := &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    http.DefaultClient,
+		queue:   defaultQueue(),
+	}
+
+	for _, opt := range opts {
+		err = opt(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Параметры очереди запросов частей по умолчанию: последовательная выгрузка с паузой между запросами.
+func defaultQueue() queueCfg {
+	return queueCfg{
+		pageSize: pageSize,
+		workers:  1,
+		pause:    10 * time.Millisecond,
+	}
+}
+
+// Опция. Использование указанного http клиента.
+//
+// Параметры:
+//
+// client - указатель на http клиент.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) error {
+		if client == nil {
+			return errors.New("client -> нет указателя на http клиент")
+		}
+		c.http = client
+		return nil
+	}
+}
+
+// Опция. Создание https клиента с доверенным CA-сертификатом сервера.
+//
+// Параметры:
+//
+// path - путь к CA-сертификату.
+func WithCACert(path string) Option {
+	return func(c *Client) error {
+		client, err := NewHttpsClient(path)
+		if err != nil {
+			return fmt.Errorf("client -> %v", err)
+		}
+		c.http = client
+		return nil
+	}
+}
+
+// Опция. Крайний срок каждого отдельного запроса к серверу.
+// Защищает от зависания выгрузки при потере связи. Значение 0 - без ограничения.
+//
+// Параметры:
+//
+// d - длительность ожидания ответа сервера.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(c *Client) error {
+		if d < 0 {
+			return errors.New("client -> отрицательное значение времени ожидания запроса")
+		}
+		c.timeout = d
+		return nil
+	}
+}
+
+// Опция. Политика повторов запросов при временных сбоях. Применяется ко всем запросам к серверу.
+//
+// Параметры:
+//
+// p - политика повторов.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) error {
+		if p.MaxAttempts < 1 {
+			return errors.New("client -> количество попыток должно быть больше нуля")
+		}
+		if p.BaseDelay < 0 || p.MaxDelay < 0 {
+			return errors.New("client -> отрицательное значение задержки повтора")
+		}
+		if p.Jitter < 0 || p.Jitter > 1 {
+			return errors.New("client -> доля разброса задержки должна быть в диапазоне 0..1")
+		}
+		c.retry = p
+		return nil
+	}
+}
+
+// Опция. Количество параллельных запросов частей при выгрузке.
+// Значение 1 - последовательная выгрузка с паузой между запросами (для слабых серверов).
+//
+// Параметры:
+//
+// n - количество исполнителей.
+func WithWorkers(n int) Option {
+	return func(c *Client) error {
+		if n < 1 {
+			return errors.New("client -> количество исполнителей должно быть больше нуля")
+		}
+		c.queue.workers = n
+		return nil
+	}
+}
+
+// Опция. Количество строк в одном запросе части.
+//
+// Параметры:
+//
+// n - размер части.
+func WithPageSize(n int) Option {
+	return func(c *Client) error {
+		if n < 1 {
+			return errors.New("client -> размер части должен быть больше нуля")
+		}
+		c.queue.pageSize = n
+		return nil
+	}
+}
+
+// Опция. Адаптивный размер части: рост при быстрых ответах, уменьшение после превышения времени
+// ожидания или ответа 5xx. Начальный размер задаётся WithPageSize. Выгрузка выполняется последовательно.
+//
+// Параметры:
+//
+// ad - параметры адаптивного размера части.
+func WithAdaptivePaging(ad AdaptivePaging) Option {
+	return func(c *Client) error {
+		if ad.MinSize < 1 {
+			return errors.New("client -> минимальный размер части должен быть больше нуля")
+		}
+		if ad.MaxSize < ad.MinSize {
+			return errors.New("client -> максимальный размер части меньше минимального")
+		}
+		if ad.Fast <= 0 {
+			return errors.New("client -> не задано время быстрого ответа")
+		}
+		c.queue.adaptive = &ad
+		return nil
+	}
+}
+
+// Опция. Отображение хода выгрузки частей.
+//
+// Параметры:
+//
+// fn - функция, принимающая количество принятых строк и общее количество строк.
+func WithProgress(fn func(done, total int)) Option {
+	return func(c *Client) error {
+		c.queue.progress = fn
+		return nil
+	}
+}
+
+// Создание HTTPS клиента с доверенным CA-сертификатом. Возвращается https клиент и ошибка.
+//
+// Параметры:
+//
+// path - путь к CA-сертификату.
+func NewHttpsClient(path string) (*http.Client, error) {
+
+	if path == "" {
+		return nil, errors.New("пустое значение пути к CA-сертификату")
+	}
+
+	cacert, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении CA-сертификата: {%v}", err)
+	}
+
+	certPool := x509.NewCertPool()
+	if ok := certPool.AppendCertsFromPEM(cacert); !ok {
+		return nil, errors.New("не удалось добавить CA-сертификат в пул")
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs: certPool,
+			},
+		},
+	}
+
+	return client, nil
+}
+
+// Полный URL запроса по пути.
+func (c *Client) url(path string) string {
+	return c.baseURL + path
+}
+
+// Адрес сервера.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Данные текущей сессии пользователя.
+func (c *Client) User() UserLogin {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.user
+}
+
+// Данные сессии для выполнения запроса. Возвращается ошибка, если регистрация не выполнена.
+func (c *Client) session() (UserLogin, error) {
+	usr := c.User()
+	if usr.Token == "" {
+		return UserLogin{}, withKind(ErrNoSession, errors.New("client -> нет регистрации на сервере"))
+	}
+	return usr, nil
+}
+
+// Контекст отдельного запроса с учётом времени ожидания клиента.
+func (c *Client) reqCtx(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// Регистрация на сервере и сохранение токена в клиенте. Возвращается ошибка.
+//
+// Параметры:
+//
+// name - имя пользователя.
+// password - пароль пользователя.
+func (c *Client) Login(name, password string) error {
+	return c.LoginCtx(context.Background(), name, password)
+}
+
+// Регистрация на сервере с учётом контекста. Возвращается ошибка.
+//
+// Параметры:
+//
+// ctx - контекст запроса.
+// name - имя пользователя.
+// password - пароль пользователя.
+func (c *Client) LoginCtx(ctx context.Context, name, password string) error {
+
+	var usr UserLogin
+	err := c.call(ctx, func(ctx context.Context) (err error) {
+		usr, err = ReqLoginServerCtx(ctx, name, password, c.url(pathLogin), c.http)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.user = usr
+	c.password = password
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Получение статуса сервера. Возвращаются данные сервера и ошибка.
+func (c *Client) Status() (RxStatusSrv, error) {
+	return c.StatusCtx(context.Background())
+}
+
+// Получение статуса сервера с учётом контекста. Возвращаются данные сервера и ошибка.
+//
+// Параметры:
+//
+// ctx - контекст запроса.
+func (c *Client) StatusCtx(ctx context.Context) (RxStatusSrv, error) {
+
+	var status RxStatusSrv
+	err := c.authCall(ctx, func(ctx context.Context, usr UserLogin) (err error) {
+		status, err = ReqStatusServerCtx(ctx, usr.Token, usr.Name, c.url(pathStatus), c.http)
+		return err
+	})
+
+	return status, err
+}
+
+// Проверка доступности сервера: запрос состояния без данных сессии. Любой ответ сервера,
+// в том числе отказ в авторизации, означает доступность. Запрос не повторяется. Возвращается ошибка.
+//
+// Параметры:
+//
+// ctx - контекст запроса.
+func (c *Client) Ping(ctx context.Context) error {
+
+	reqCtx, cancel := c.reqCtx(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, c.url(pathStatus), nil)
+	if err != nil {
+		return fmt.Errorf("ping -> ошибка создания запроса: %v", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if isCertError(err) {
+			return withKind(ErrCertificate, fmt.Errorf("ping -> ошибка сертификата сервера: %w", err))
+		}
+		return withKind(ErrNetwork, fmt.Errorf("ping -> сервер не отвечает: %w", err))
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrBody))
+	_ = resp.Body.Close()
+
+	return nil
+}
+
+// Получение количества записей в БД по дате. Возвращается количество строк и ошибка.
+//
+// Параметры:
+//
+// date - дата в формате YYYY-MM-DD.
+func (c *Client) CountRows(date string) (int, error) {
+	return c.CountRowsCtx(context.Background(), date)
+}
+
+// Получение количества записей в БД по дате с учётом контекста. Возвращается количество строк и ошибка.
+//
+// Параметры:
+//
+// ctx - контекст запроса.
+// date - дата в формате YYYY-MM-DD.
+func (c *Client) CountRowsCtx(ctx context.Context, date string) (int, error) {
+
+	var cntStr int
+	err := c.authCall(ctx, func(ctx context.Context, usr UserLogin) (err error) {
+		cntStr, err = ReqCntStrByDateDBCtx(ctx, usr.Token, usr.Name, date, c.url(pathCntStr), c.http)
+		return err
+	})
+
+	return cntStr, err
+}
+
+// Выгрузка архивных данных за день: запрос количества строк и очередь запросов частей.
+// Возвращается результат выгрузки и ошибка.
+//
+// Параметры:
+//
+// date - дата в формате YYYY-MM-DD.
+func (c *Client) FetchDay(date string) (DownloadResult, error) {
+	return c.FetchDayCtx(context.Background(), date)
+}
+
+// Выгрузка архивных данных за день с учётом контекста. Возвращается результат выгрузки и ошибка.
+// При отмене контекста возвращаются уже принятые части и ошибка *CanceledError.
+//
+// Параметры:
+//
+// ctx - контекст выгрузки.
+// date - дата в формате YYYY-MM-DD.
+func (c *Client) FetchDayCtx(ctx context.Context, date string) (DownloadResult, error) {
+
+	cntStr, err := c.CountRowsCtx(ctx, date)
+	if err != nil {
+		return DownloadResult{}, err
+	}
+
+	result := DownloadResult{
+		Date:   date,
+		CntStr: cntStr,
+	}
+
+	collect := func(part PartDataDB) error {
+		result.Pages = append(result.Pages, part)
+		return nil
+	}
+
+	result.PageSizes, err = c.fetchPages(ctx, date, cntStr, collect)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Очередь запросов частей за день с передачей каждой части в emit по порядку NumbReq.
+// Проверяет соответствие количества принятых строк ответу /cntstr.
+// Возвращаются размеры запрошенных частей и ошибка.
+//
+// Параметры:
+//
+// ctx - контекст выгрузки.
+// date - дата в формате YYYY-MM-DD.
+// cntStr - количество строк по ответу /cntstr.
+// emit - приём очередной части.
+func (c *Client) fetchPages(ctx context.Context, date string, cntStr int, emit func(PartDataDB) error) ([]int, error) {
+
+	fetch := func(ctx context.Context, numbReq, strLimit, strOffSet int) (part PartDataDB, err error) {
+
+		// Пока часть можно уменьшить, перегрузка сервера возвращается адаптивной очереди без повторов
+		retry := c.retry
+		if ad := c.queue.adaptive; ad != nil && strLimit > ad.MinSize {
+			retry.skipOverload = true
+		}
+
+		err = c.authCallRetry(ctx, retry, func(ctx context.Context, usr UserLogin) (err error) {
+			part, err = ReqPartDataDBCtx(ctx, numbReq, strLimit, strOffSet, date, usr.Token, usr.Name, c.url(pathPartDataDB), c.http)
+			return err
+		})
+		return part, err
+	}
+
+	received := 0
+	count := func(part PartDataDB) error {
+		received += len(part.Data)
+		return emit(part)
+	}
+
+	sizes, err := runQueue(ctx, cntStr, c.queue, fetch, count)
+	if err != nil {
+		return sizes, err
+	}
+
+	// Проверка количества принятых строк
+	if received != cntStr {
+		return sizes, &CountMismatchError{Expected: cntStr, Actual: received}
+	}
+
+	return sizes, nil
+}
+
+// Сведение всех принятых частей в одну структуру для сохранения.
+func (r DownloadResult) DataDB() RxDataDB {
+
+	data := RxDataDB{
+		StartDate: r.Date,
+		Data:      make([]DataEl, 0, r.CntStr),
+	}
+	for _, v := range r.Pages {
+		data.Data = append(data.Data, v.Data...)
+	}
+
+	return data
+}
@@ -2,13 +2,11 @@ package clientapi
 
 import (
 	"bytes"
-	"crypto/tls"
-	"crypto/x509"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"os"
@@ -16,6 +14,9 @@ import (
 	"time"
 )
 
+// Количество строк в одном запросе части
+const pageSize = 100
+
 type (
 	// Для приёма количества строк
 	CntStrT struct {
@@ -68,6 +69,7 @@ type (
 	// JSON для приёма архивных данных БД
 	RxDataDB struct {
 		StartDate string   `json:"startdate"`
+		EndDate   string   `json:"enddate,omitempty"` // конечная дата при выгрузке диапазона
 		Data      []DataEl `json:"datadb"`
 	}
 	DataEl struct {
@@ -103,19 +105,32 @@ type (
 // u - URL.
 // client - указатель на созданный https клиент.
 func ReqStatusServer(token, name, u string, client *http.Client) (dataRx RxStatusSrv, err error) {
+	return ReqStatusServerCtx(context.Background(), token, name, u, client)
+}
+
+// Получение статуса сервера с учётом контекста. Возвращаются данные сервера и ошибка.
+//
+// Парметры:
+//
+// ctx - контекст запроса (отмена и крайний срок).
+// token - токен пользователя.
+// name - имя пользователя.
+// u - URL.
+// client - указатель на созданный https клиент.
+func ReqStatusServerCtx(ctx context.Context, token, name, u string, client *http.Client) (dataRx RxStatusSrv, err error) {
 
 	// Проверка аргементов
 	if token == "" {
-		return RxStatusSrv{}, errors.New("req-status -> пустое значение аргумента token")
+		return RxStatusSrv{}, withKind(ErrInvalidArg, errors.New("req-status -> пустое значение аргумента token"))
 	}
 	if name == "" {
-		return RxStatusSrv{}, errors.New("req-status -> пустое значение аргумента name")
+		return RxStatusSrv{}, withKind(ErrInvalidArg, errors.New("req-status -> пустое значение аргумента name"))
 	}
 	if client == nil {
-		return RxStatusSrv{}, errors.New("req-status -> нет ссылки на http клиент")
+		return RxStatusSrv{}, withKind(ErrInvalidArg, errors.New("req-status -> нет ссылки на http клиент"))
 	}
 	if u == "" {
-		return RxStatusSrv{}, errors.New("req-status -> пустое значение URL")
+		return RxStatusSrv{}, withKind(ErrInvalidArg, errors.New("req-status -> пустое значение URL"))
 	}
 
 	// Тело запроса
@@ -131,7 +146,7 @@ func ReqStatusServer(token, name, u string, client *http.Client) (dataRx RxStatu
 	reqBody := bytes.NewBuffer(bytesBody)
 
 	// Формирование запроса
-	req, err := http.NewRequest(http.MethodPost, u, reqBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, reqBody)
 	if err != nil {
 		return RxStatusSrv{}, fmt.Errorf("req-status -> ошибка создания запроса: %v", err)
 	}
@@ -140,24 +155,24 @@ func ReqStatusServer(token, name, u string, client *http.Client) (dataRx RxStatu
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return RxStatusSrv{}, fmt.Errorf("req-status -> ошибка запроса: %v", err)
+		return RxStatusSrv{}, withKind(requestKind(err), fmt.Errorf("req-status -> ошибка запроса: %w", err))
 	}
 	defer func() {
 		_ = resp.Body.Close()
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return RxStatusSrv{}, fmt.Errorf("req-status -> нет успешности запроса")
+		return RxStatusSrv{}, fmt.Errorf("req-status -> нет успешности запроса: %w", newHTTPError(pathStatus, resp))
 	}
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return RxStatusSrv{}, fmt.Errorf("req-status -> ошибка при чтении тела ответа: %v", err)
+		return RxStatusSrv{}, withKind(ErrNetwork, fmt.Errorf("req-status -> ошибка при чтении тела ответа: %w", err))
 	}
 
 	err = json.Unmarshal(respBody, &dataRx)
 	if err != nil {
-		return RxStatusSrv{}, fmt.Errorf("req-status -> ошибка обработки данных ответа: %v", err)
+		return RxStatusSrv{}, withKind(ErrDecode, fmt.Errorf("req-status -> ошибка обработки данных ответа: %w", err))
 	}
 
 	return dataRx, nil
@@ -173,26 +188,40 @@ func ReqStatusServer(token, name, u string, client *http.Client) (dataRx RxStatu
 // u - URL.
 // client - указатель на созданный https клиент.
 func ReqCntStrByDateDB(token, name, startDate, u string, client *http.Client) (cntStr int, err error) {
+	return ReqCntStrByDateDBCtx(context.Background(), token, name, startDate, u, client)
+}
+
+// Получение количества записей в БД по указанной дате с учётом контекста. Возвращаются количество строк и ошибку.
+//
+// Парметры:
+//
+// ctx - контекст запроса (отмена и крайний срок).
+// token - токен пользователя.
+// name - имя пользователя.
+// startDate - дата для выполнения экспорта данных.
+// u - URL.
+// client - указатель на созданный https клиент.
+func ReqCntStrByDateDBCtx(ctx context.Context, token, name, startDate, u string, client *http.Client) (cntStr int, err error) {
 
 	// Проверка аргементов
 	if token == "" {
-		return 0, errors.New("req-cntStr -> пустое значение аргумента token")
+		return 0, withKind(ErrInvalidArg, errors.New("req-cntStr -> пустое значение аргумента token"))
 	}
 	if name == "" {
-		return 0, errors.New("req-cntStr -> пустое значение аргумента name")
+		return 0, withKind(ErrInvalidArg, errors.New("req-cntStr -> пустое значение аргумента name"))
 	}
 	if client == nil {
-		return 0, errors.New("req-cntStr -> нет ссылки на https клиент")
+		return 0, withKind(ErrInvalidArg, errors.New("req-cntStr -> нет ссылки на https клиент"))
 	}
 	if startDate == "" {
-		return 0, errors.New("req-cntStr -> пустое значение даты")
+		return 0, withKind(ErrInvalidArg, errors.New("req-cntStr -> пустое значение даты"))
 	}
 	_, err = time.Parse("2006-01-02", startDate)
 	if err != nil {
-		return 0, errors.New("req-cntStr -> принятая дата не в формате YYYY-MM-DD")
+		return 0, withKind(ErrBadDate, errors.New("req-cntStr -> принятая дата не в формате YYYY-MM-DD"))
 	}
 	if u == "" {
-		return 0, errors.New("req-cntStr -> пустое значение аргумента URL")
+		return 0, withKind(ErrInvalidArg, errors.New("req-cntStr -> пустое значение аргумента URL"))
 	}
 
 	// Тело запроса
@@ -209,7 +238,7 @@ func ReqCntStrByDateDB(token, name, startDate, u string, client *http.Client) (c
 	reqBody := bytes.NewBuffer(bytesBody)
 
 	// Формирование запроса
-	req, err := http.NewRequest(http.MethodPost, u, reqBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, reqBody)
 	if err != nil {
 		return 0, fmt.Errorf("req-cntStr -> ошибка формирования запроса: {%v}", err)
 	}
@@ -218,18 +247,19 @@ func ReqCntStrByDateDB(token, name, startDate, u string, client *http.Client) (c
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return 0, fmt.Errorf("req-cntStr -> ошибка выполнения запроса к серверу: {%v}", err)
+		return 0, withKind(requestKind(err), fmt.Errorf("req-cntStr -> ошибка выполнения запроса к серверу: {%w}", err))
 	}
 
 	// Статус ответа
 	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("req-cntStr -> сервер вернул не код 200")
+		defer resp.Body.Close()
+		return 0, fmt.Errorf("req-cntStr -> сервер вернул не код 200: %w", newHTTPError(pathCntStr, resp))
 	}
 
 	// Ответ
 	dataResp, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return 0, fmt.Errorf("req-cntStr -> ошибка чтения тела ответа: {%v}", err)
+		return 0, withKind(ErrNetwork, fmt.Errorf("req-cntStr -> ошибка чтения тела ответа: {%w}", err))
 	}
 	defer func() {
 		_ = resp.Body.Close()
@@ -239,12 +269,12 @@ func ReqCntStrByDateDB(token, name, startDate, u string, client *http.Client) (c
 
 	err = json.Unmarshal(dataResp, &rxJson)
 	if err != nil {
-		return 0, fmt.Errorf("req-cntStr -> ошибка при десериализации принятых данных от сервера: {%v}", err)
+		return 0, withKind(ErrDecode, fmt.Errorf("req-cntStr -> ошибка при десериализации принятых данных от сервера: {%w}", err))
 	}
 
 	cntStr, err = strconv.Atoi(rxJson.CntStr)
 	if err != nil {
-		return 0, fmt.Errorf("req-cntStr -> принятое значение {%s} не является числов", rxJson.CntStr)
+		return 0, withKind(ErrDecode, fmt.Errorf("req-cntStr -> принятое значение {%s} не является числов", rxJson.CntStr))
 	}
 
 	return cntStr, nil
@@ -259,44 +289,58 @@ func ReqCntStrByDateDB(token, name, startDate, u string, client *http.Client) (c
 // u - URL.
 // client - указатель на https клиент.
 func ReqLoginServer(name, password, u string, client *http.Client) (user UserLogin, err error) {
+	return ReqLoginServerCtx(context.Background(), name, password, u, client)
+}
+
+// Регистрация на сервере с учётом контекста. Возвращаются данные пользователя и ошибка.
+//
+// Параметры:
+//
+// ctx - контекст запроса (отмена и крайний срок).
+// name - имя пользователя.
+// password - пароль пользователя.
+// u - URL.
+// client - указатель на https клиент.
+func ReqLoginServerCtx(ctx context.Context, name, password, u string, client *http.Client) (user UserLogin, err error) {
 
 	// Проверка аргументов
 	if name == "" {
-		return UserLogin{}, errors.New("login -> нет содержимого в аргументе name")
+		return UserLogin{}, withKind(ErrInvalidArg, errors.New("login -> нет содержимого в аргументе name"))
 	}
 	if password == "" {
-		return UserLogin{}, errors.New("login -> нет содержимого в аргументе password")
+		return UserLogin{}, withKind(ErrInvalidArg, errors.New("login -> нет содержимого в аргументе password"))
 	}
 	if u == "" {
-		return UserLogin{}, errors.New("login -> нет содержимого в аргументе u")
+		return UserLogin{}, withKind(ErrInvalidArg, errors.New("login -> нет содержимого в аргументе u"))
 	}
 	if client == nil {
-		return UserLogin{}, errors.New("login -> нет содержимого в указателе на Http клиент")
+		return UserLogin{}, withKind(ErrInvalidArg, errors.New("login -> нет содержимого в указателе на Http клиент"))
 	}
 
 	body := bytes.NewBuffer([]byte(fmt.Sprintf("%s %s", name, password)))
 
 	// Формирование запроса
-	req, err := http.NewRequest(http.MethodPost, u, body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, body)
 	if err != nil {
-		return UserLogin{}, errors.New("login -> ошибка при создании запроса регистрации на сервере")
+		return UserLogin{}, fmt.Errorf("login -> ошибка при создании запроса регистрации на сервере: {%w}", err)
 	}
 
 	// Запрос
 	resp, err := client.Do(req)
 	if err != nil {
-		return UserLogin{}, errors.New("login -> ошибка при выполнении запроса к https серверу")
+		return UserLogin{}, withKind(requestKind(err), fmt.Errorf("login -> ошибка при выполнении запроса к https серверу: {%w}", err))
 	}
 
 	// Проверка статус-кода ответа
 	if resp.StatusCode != http.StatusOK {
-		return UserLogin{}, errors.New("login -> ошибка, сервер не вернул код 200")
+		defer resp.Body.Close()
+		return UserLogin{}, fmt.Errorf("login -> ошибка, сервер не вернул код 200: %w", newHTTPError(pathLogin, resp))
 	}
 
 	// Ответ
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return UserLogin{}, errors.New("login -> ошибка при чтении тела ответа сервера")
+		return UserLogin{}, withKind(ErrNetwork, fmt.Errorf("login -> ошибка при чтении тела ответа сервера: {%w}", err))
 	}
 	defer func() {
 		_ = resp.Body.Close()
@@ -306,7 +350,7 @@ func ReqLoginServer(name, password, u string, client *http.Client) (user UserLog
 
 	err = json.Unmarshal(respBody, &dataRx)
 	if err != nil {
-		return UserLogin{}, errors.New("login -> ошибка при десериализации принятых данных")
+		return UserLogin{}, withKind(ErrDecode, fmt.Errorf("login -> ошибка при десериализации принятых данных: {%w}", err))
 	}
 
 	// Фиксация данных
@@ -329,35 +373,52 @@ func ReqLoginServer(name, password, u string, client *http.Client) (user UserLog
 // u - URL.
 // client - указатель на https клиента.
 func ReqPartDataDB(numbReg, strLimit, strOffSet int, dateDB, token, name, u string, client *http.Client) (data PartDataDB, err error) {
+	return ReqPartDataDBCtx(context.Background(), numbReg, strLimit, strOffSet, dateDB, token, name, u, client)
+}
+
+// Частичный запрос строк БД с учётом контекста. Возвращается результат запроса и ошибка.
+//
+// Параметры:
+//
+// ctx - контекст запроса (отмена и крайний срок).
+// numbReq - номер запроса.
+// strLimit - количество строк.
+// strOffSet - смещение номеров строк.
+// dataDB - дата.
+// token - токен.
+// name - имя пользователя.
+// u - URL.
+// client - указатель на https клиента.
+func ReqPartDataDBCtx(ctx context.Context, numbReg, strLimit, strOffSet int, dateDB, token, name, u string, client *http.Client) (data PartDataDB, err error) {
 
 	// Проверка значений аргументов
 	if numbReg < 0 {
-		return PartDataDB{}, errors.New("req-partdatadb -> значение аргумента numbReg, меньше нуля")
+		return PartDataDB{}, withKind(ErrInvalidArg, errors.New("req-partdatadb -> значение аргумента numbReg, меньше нуля"))
 	}
 	if strLimit < 0 {
-		return PartDataDB{}, errors.New("req-partdatadb -> значение аргумента strLimit, меньше нуля")
+		return PartDataDB{}, withKind(ErrInvalidArg, errors.New("req-partdatadb -> значение аргумента strLimit, меньше нуля"))
 	}
 	if strOffSet < 0 {
-		return PartDataDB{}, errors.New("req-partdatadb -> значение аргумента strOffSet, меньше нуля")
+		return PartDataDB{}, withKind(ErrInvalidArg, errors.New("req-partdatadb -> значение аргумента strOffSet, меньше нуля"))
 	}
 	if dateDB == "" {
-		return PartDataDB{}, errors.New("req-partdatadb -> пустое значение даты")
+		return PartDataDB{}, withKind(ErrInvalidArg, errors.New("req-partdatadb -> пустое значение даты"))
 	}
 	_, err = time.Parse("2006-01-02", dateDB)
 	if err != nil {
-		return PartDataDB{}, errors.New("req-partdatadb -> значение даты не в формате YYYY-MM-DD")
+		return PartDataDB{}, withKind(ErrBadDate, errors.New("req-partdatadb -> значение даты не в формате YYYY-MM-DD"))
 	}
 	if token == "" {
-		return PartDataDB{}, errors.New("req-partdatadb -> пустое значение токена")
+		return PartDataDB{}, withKind(ErrInvalidArg, errors.New("req-partdatadb -> пустое значение токена"))
 	}
 	if name == "" {
-		return PartDataDB{}, errors.New("req-partdatadb -> пустое значение имени")
+		return PartDataDB{}, withKind(ErrInvalidArg, errors.New("req-partdatadb -> пустое значение имени"))
 	}
 	if u == "" {
-		return PartDataDB{}, errors.New("req-partdatadb -> пустое содержимое URL")
+		return PartDataDB{}, withKind(ErrInvalidArg, errors.New("req-partdatadb -> пустое содержимое URL"))
 	}
 	if client == nil {
-		return PartDataDB{}, errors.New("req-partdatadb -> нет указателя на https клиент")
+		return PartDataDB{}, withKind(ErrInvalidArg, errors.New("req-partdatadb -> нет указателя на https клиент"))
 	}
 
 	// Тело запроса
@@ -376,7 +437,7 @@ func ReqPartDataDB(numbReg, strLimit, strOffSet int, dateDB, token, name, u stri
 	// Параметры запроса
 	parseU, err := url.Parse(u)
 	if err != nil {
-		return PartDataDB{}, errors.New("req-partdatadb -> ошибка парсинга URL")
+		return PartDataDB{}, withKind(ErrInvalidArg, fmt.Errorf("req-partdatadb -> ошибка парсинга URL: {%w}", err))
 	}
 	qP := url.Values{}
 	qP.Set("numbReg", fmt.Sprintf("%d", numbReg))
@@ -386,7 +447,7 @@ func ReqPartDataDB(numbReg, strLimit, strOffSet int, dateDB, token, name, u stri
 	parseU.RawQuery = qP.Encode()
 
 	// Формирование запроса
-	req, err := http.NewRequest(http.MethodPost, parseU.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, parseU.String(), reqBody)
 	if err != nil {
 		return PartDataDB{}, fmt.Errorf("req-partdatadb -> ошибка формирования запроса {%v}", err)
 	}
@@ -396,18 +457,19 @@ func ReqPartDataDB(numbReg, strLimit, strOffSet int, dateDB, token, name, u stri
 	// Запрос к серверу
 	resp, err := client.Do(req)
 	if err != nil {
-		return PartDataDB{}, fmt.Errorf("req-partdatadb -> ошибка выполнения запроса {%v}", err)
+		return PartDataDB{}, withKind(requestKind(err), fmt.Errorf("req-partdatadb -> ошибка выполнения запроса {%w}", err))
 	}
 
 	// Проверка статус-кода ответа сервера на 200
 	if resp.StatusCode != http.StatusOK {
-		return PartDataDB{}, errors.New("req-partdatadb -> сервер не вернул код 200")
+		defer resp.Body.Close()
+		return PartDataDB{}, fmt.Errorf("req-partdatadb -> сервер не вернул код 200: %w", newHTTPError(pathPartDataDB, resp))
 	}
 
 	// Обработка ответа
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return PartDataDB{}, fmt.Errorf("req-partdatadb -> ошибка чтения тела ответа {%v}", err)
+		return PartDataDB{}, withKind(ErrNetwork, fmt.Errorf("req-partdatadb -> ошибка чтения тела ответа {%w}", err))
 	}
 	defer func() {
 		_ = resp.Body.Close()
@@ -415,39 +477,15 @@ func ReqPartDataDB(numbReg, strLimit, strOffSet int, dateDB, token, name, u stri
 
 	err = json.Unmarshal(body, &data)
 	if err != nil {
-		return PartDataDB{}, fmt.Errorf("req-partdatadb -> ошибка десиарелизации ответа {%v}", err)
+		return PartDataDB{}, withKind(ErrDecode, fmt.Errorf("req-partdatadb -> ошибка десиарелизации ответа {%w}", err))
 	}
 
 	return data, nil
 }
 
-// Создание HTTPS клиента. Функция возвращает https клиент и ошибку
+// Создание HTTPS клиента по пути к сертификату из переменной окружения HTTPS_SERVER_KEY_PUBLIC. Функция возвращает https клиент и ошибку
 func CreateHttpsClient() (client *http.Client, err error) {
-
-	// Загрузка сертификатов
-	certPool := x509.NewCertPool()
-
-	// Путь к сертификату
-	cacert, err := os.ReadFile(os.Getenv("HTTPS_SERVER_KEY_PUBLIC"))
-	if err != nil {
-		log.Fatalf("ошибка при чтении CA-сертификата: %v", err)
-	}
-
-	// Добавление сертификатав пул доверенных сертификатов
-	if ok := certPool.AppendCertsFromPEM(cacert); !ok {
-		log.Fatal("не удалось добавить CA-сертификат в пул")
-	}
-
-	// Настройка клиент с TLS конфигурацией
-	client = &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				RootCAs: certPool, // пул сертификатов CA
-			},
-		},
-	}
-
-	return client, nil
+	return NewHttpsClient(os.Getenv("HTTPS_SERVER_KEY_PUBLIC"))
 }
 
 // Функция реализует очередь запросов на сервер для выгрузки исходных данных. Возвращает ошибку.
@@ -460,30 +498,57 @@ func CreateHttpsClient() (client *http.Client, err error) {
 // u - URL.
 // cntStr - количество запрашиваемых строк.
 // client - указатель на https клиента.
-func QueReqPartDataDB(startDate, token, name, u string, cntStr int, client *http.Client) (rxRataDB []PartDataDB, err error) {
+// opts - параметры очереди запросов (WithWorkers, WithPageSize, WithAdaptivePageSize).
+func QueReqPartDataDB(startDate, token, name, u string, cntStr int, client *http.Client, opts ...Option) (rxRataDB []PartDataDB, err error) {
+	return QueReqPartDataDBCtx(context.Background(), startDate, token, name, u, cntStr, client, opts...)
+}
+
+// Очередь запросов на сервер для выгрузки исходных данных с учётом контекста. Возвращает принятые части и ошибку.
+// При отмене контекста очередь останавливается между запросами, возвращаются уже принятые части и ошибка *CanceledError.
+//
+// Параметры:
+//
+// ctx - контекст выгрузки (отмена и крайний срок).
+// startDate - дата экспорта данных.
+// token - токен регистрации.
+// name - имя пользователя.
+// u - URL.
+// cntStr - количество запрашиваемых строк.
+// client - указатель на https клиента.
+// opts - параметры очереди запросов (WithWorkers, WithPageSize, WithAdaptivePageSize), без опций - последовательная выгрузка.
+func QueReqPartDataDBCtx(ctx context.Context, startDate, token, name, u string, cntStr int, client *http.Client, opts ...Option) (rxRataDB []PartDataDB, err error) {
 
 	// Проверка аргументов
 	if startDate == "" {
-		return []PartDataDB{}, errors.New("queReq -> пустое значение даты")
+		return []PartDataDB{}, withKind(ErrInvalidArg, errors.New("queReq -> пустое значение даты"))
 	}
 	_, err = time.Parse("2006-01-02", startDate)
 	if err != nil {
-		return []PartDataDB{}, errors.New("queReq -> значение даты не в формате YYYY-MM-DD")
+		return []PartDataDB{}, withKind(ErrBadDate, errors.New("queReq -> значение даты не в формате YYYY-MM-DD"))
 	}
 	if token == "" {
-		return []PartDataDB{}, errors.New("queReq -> пустое значение token")
+		return []PartDataDB{}, withKind(ErrInvalidArg, errors.New("queReq -> пустое значение token"))
 	}
 	if name == "" {
-		return []PartDataDB{}, errors.New("queReq -> пустое значение name")
+		return []PartDataDB{}, withKind(ErrInvalidArg, errors.New("queReq -> пустое значение name"))
 	}
 	if u == "" {
-		return []PartDataDB{}, errors.New("queReq -> пустое значение URL")
+		return []PartDataDB{}, withKind(ErrInvalidArg, errors.New("queReq -> пустое значение URL"))
 	}
 	if cntStr < 0 {
-		return []PartDataDB{}, errors.New("queReq -> в количестве строк отрицательное число")
+		return []PartDataDB{}, withKind(ErrInvalidArg, errors.New("queReq -> в количестве строк отрицательное число"))
 	}
 	if client == nil {
-		return []PartDataDB{}, errors.New("queReq -> нет указателя на https клиента")
+		return []PartDataDB{}, withKind(ErrInvalidArg, errors.New("queReq -> нет указателя на https клиента"))
+	}
+
+	// Параметры очереди из опций
+	c := &Client{queue: defaultQueue()}
+	for _, opt := range opts {
+		err = opt(c)
+		if err != nil {
+			return []PartDataDB{}, withKind(ErrInvalidArg, fmt.Errorf("queReq -> %w", err))
+		}
 	}
 
 	// Если количество строк в запросе = 0
@@ -493,36 +558,67 @@ func QueReqPartDataDB(startDate, token, name, u string, cntStr int, client *http
 
 	collectRxDataDB := make([]PartDataDB, 0)
 
-	// Вычисление количества необходимых запросов
-	iter := cntStr / 100
+	cfg := c.queue
+	cfg.progress = PrintProgress
+	fetch := func(ctx context.Context, numbReq, strLimit, strOffSet int) (PartDataDB, error) {
+		return ReqPartDataDBCtx(ctx, numbReq, strLimit, strOffSet, startDate, token, name, u, client)
+	}
+	collect := func(part PartDataDB) error {
+		collectRxDataDB = append(collectRxDataDB, part)
+		return nil
+	}
 
 	// Запросы
-	if iter == 0 {
+	_, err = runQueue(ctx, cntStr, cfg, fetch, collect)
+	if err != nil {
+		// Завершение строки хода выгрузки, прерванной до приёма всех строк
+		fmt.Println()
 
-		rxData, err := ReqPartDataDB(0, 100, 0, startDate, token, name, u, client)
-		if err != nil {
-			return []PartDataDB{}, errors.New("queReq -> ошибка при выполнении запроса при количестве строк < 100")
+		var errCancel *CanceledError
+		if errors.As(err, &errCancel) {
+			return collectRxDataDB, err
 		}
-		collectRxDataDB = append(collectRxDataDB, rxData)
+		return []PartDataDB{}, err
+	}
 
-	} else {
+	// Проверка количества принятых строк
+	err = checkCntStr(cntStr, collectRxDataDB)
+	if err != nil {
+		return collectRxDataDB, err
+	}
 
-		for i := 0; i < iter; i++ {
+	return collectRxDataDB, nil
+}
 
-			// отображение процентов выполнения получения данных от сервера
-			percentage := float64(i+1) / float64(iter) * 100
-			fmt.Printf("Загрузка данных: %.1f%%\r", percentage)
+// Отображение процентов выполнения получения данных от сервера. После приёма всех строк строка завершается.
+//
+// Параметры:
+//
+// done - количество принятых строк.
+// total - общее количество строк.
+func PrintProgress(done, total int) {
+	percentage := float64(done) / float64(total) * 100
+	fmt.Printf("Загрузка данных: %.1f%%\r", percentage)
+	if done == total {
+		fmt.Println()
+	}
+}
 
-			rxData, err := ReqPartDataDB(i, 100, 100*i, startDate, token, name, u, client)
-			if err != nil {
-				return []PartDataDB{}, fmt.Errorf("queReq -> ошибка при выполнении запроса при количестве строк >= 100, на итерации {%d}, {%v}", i, err)
-			}
-			collectRxDataDB = append(collectRxDataDB, rxData)
+// Проверка соответствия количества принятых строк ответу /cntstr. Возвращается ошибка *CountMismatchError.
+//
+// Параметры:
+//
+// cntStr - ожидаемое количество строк.
+// parts - принятые части.
+func checkCntStr(cntStr int, parts []PartDataDB) error {
 
-			time.Sleep(10 * time.Millisecond) // установка небольшой паузы между очередным запросом
-		}
+	received := 0
+	for _, v := range parts {
+		received += len(v.Data)
+	}
+	if received != cntStr {
+		return &CountMismatchError{Expected: cntStr, Actual: received}
 	}
-	fmt.Println()
 
-	return collectRxDataDB, nil
+	return nil
 }
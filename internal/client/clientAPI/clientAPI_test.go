@@ -2,6 +2,7 @@ package clientapi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -254,7 +255,7 @@ func Test_ReqStatusServer_Error(t *testing.T) {
 			name:      "test",
 			useURL:    "true",
 			useClient: "true",
-			wantErr:   "req-status -> нет успешности запроса",
+			wantErr:   "req-status -> нет успешности запроса: запрос {/status}, код ответа {400}, тело ответа {Bad Request}",
 		},
 		{
 			nameTest:  "пустое значение имени пользователя",
@@ -492,7 +493,7 @@ func Test_ReqCntStrByDateDB_Error(t *testing.T) {
 			date:      "2025-05-05",
 			useURL:    "true",
 			useClient: "true",
-			wantErr:   "req-cntStr -> сервер вернул не код 200",
+			wantErr:   "req-cntStr -> сервер вернул не код 200: запрос {/cntstr}, код ответа {400}, тело ответа {Bad Request}",
 		},
 		{
 			nameTest:  "пустое значение имени пользователя",
@@ -851,7 +852,7 @@ func Test_ReqPartDataDB_Error(t *testing.T) {
 			name:      "test2",
 			useURL:    "true",
 			useClient: "true",
-			wantError: "req-partdatadb -> сервер не вернул код 200",
+			wantError: "req-partdatadb -> сервер не вернул код 200: запрос {/partdatadb}, код ответа {400}, тело ответа {Bad Request}",
 		},
 	}
 
@@ -976,6 +977,22 @@ func Test_QueReqPartDataDB_Success(t *testing.T) {
 			cntStr:     1000,
 			wantRxSize: 1000,
 		},
+		{
+			nameTest:   "количество строк = 50",
+			startDate:  "2025-01-01",
+			token:      usrToken,
+			name:       usrName,
+			cntStr:     50,
+			wantRxSize: 50,
+		},
+		{
+			nameTest:   "количество строк = 250",
+			startDate:  "2025-01-01",
+			token:      usrToken,
+			name:       usrName,
+			cntStr:     250,
+			wantRxSize: 250,
+		},
 	}
 
 	// Сервер
@@ -1055,9 +1072,26 @@ func Test_QueReqPartDataDB_Success(t *testing.T) {
 		}
 		rxCnt := len(saveData)
 		assert.Equalf(t, tt.wantRxSize, rxCnt, "ожидалось %d записей, а принято: %d", tt.wantRxSize, rxCnt)
+		assert.Equalf(t, simDataDB3600[:tt.wantRxSize], saveData, "нет соответствия принятых строк при количестве {%d}", tt.cntStr)
 	}
 }
 
+// Очередь запросов на загрузку архивных данных (сервер вернул меньше строк, чем сообщил)
+func Test_QueReqPartDataDB_CountMismatch(t *testing.T) {
+
+	date := "2025-01-01"
+	fs := newFakeServer(t, map[string][]DataEl{date: simRows(360)})
+
+	rxData, err := QueReqPartDataDB(date, fs.token, fs.name, fs.URL+pathPartDataDB, 400, fs.Client())
+	require.Error(t, err, "ожидалась ошибка несоответствия количества строк")
+
+	var errCnt *CountMismatchError
+	require.Truef(t, errors.As(err, &errCnt), "ожидалась ошибка *CountMismatchError, а принято: {%v}", err)
+	assert.Equal(t, 400, errCnt.Expected)
+	assert.Equal(t, 360, errCnt.Actual)
+	assert.Len(t, rxData, 4, "принятые части должны возвращаться вместе с ошибкой")
+}
+
 // Очередь запросов на загрузку архивных данных (если строк меньше 100)
 func Test_QueReqPartDataDB_Error(t *testing.T) {
 
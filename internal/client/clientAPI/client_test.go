@@ -0,0 +1,402 @@
+package clientapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Имитация сервера BlackBox
+type fakeServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	name     string
+	password string
+	token    string
+	status   RxStatusSrv
+	data     map[string][]DataEl
+	requests map[string]int
+
+	// Перехват запроса до обработки. Возвращает true, если ответ уже сформирован.
+	hook func(w http.ResponseWriter, r *http.Request) bool
+}
+
+// Создание имитации сервера BlackBox.
+//
+// Параметры:
+//
+// data - архивные данные по датам.
+func newFakeServer(t *testing.T, data map[string][]DataEl) *fakeServer {
+	t.Helper()
+
+	fs := &fakeServer{
+		name:     "test",
+		password: "123",
+		token:    "1234567890",
+		status:   RxStatusSrv{TimeStart: "22-05-2025 02:18:15"},
+		data:     data,
+		requests: make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathLogin, fs.handleLogin)
+	mux.HandleFunc(pathStatus, fs.handleStatus)
+	mux.HandleFunc(pathCntStr, fs.handleCntStr)
+	mux.HandleFunc(pathPartDataDB, fs.handlePartDataDB)
+
+	fs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fs.mu.Lock()
+		fs.requests[r.URL.Path]++
+		hook := fs.hook
+		fs.mu.Unlock()
+		if hook != nil && hook(w, r) {
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(fs.Close)
+
+	return fs
+}
+
+// Установка перехвата запросов.
+func (fs *fakeServer) setHook(hook func(w http.ResponseWriter, r *http.Request) bool) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.hook = hook
+}
+
+// Смена токена сервера (имитация перезапуска сервера или истечения токена).
+func (fs *fakeServer) rotateToken(token string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.token = token
+}
+
+// Количество запросов по пути.
+func (fs *fakeServer) count(path string) int {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.requests[path]
+}
+
+// Проверка токена запроса.
+func (fs *fakeServer) authorized(r *http.Request) bool {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return r.Header.Get("authorization") == fs.token
+}
+
+// Чтение имени и даты из тела запроса.
+func readDateName(r *http.Request) (DateNameT, error) {
+	var rx DateNameT
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return rx, err
+	}
+	err = json.Unmarshal(b, &rx)
+	return rx, err
+}
+
+func (fs *fakeServer) handleLogin(w http.ResponseWriter, r *http.Request) {
+	b, _ := io.ReadAll(r.Body)
+	if string(b) != fs.name+" "+fs.password {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	fs.mu.Lock()
+	tx, _ := json.Marshal(TokenT{Token: fs.token})
+	fs.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(tx)
+}
+
+func (fs *fakeServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if !fs.authorized(r) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	tx, _ := json.Marshal(fs.status)
+	w.WriteHeader(http.StatusOK)
+	w.Write(tx)
+}
+
+func (fs *fakeServer) handleCntStr(w http.ResponseWriter, r *http.Request) {
+	if !fs.authorized(r) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	rx, err := readDateName(r)
+	if err != nil || rx.Name != fs.name {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	tx, _ := json.Marshal(CntStrT{CntStr: strconv.Itoa(len(fs.data[rx.Date]))})
+	w.WriteHeader(http.StatusOK)
+	w.Write(tx)
+}
+
+func (fs *fakeServer) handlePartDataDB(w http.ResponseWriter, r *http.Request) {
+	if !fs.authorized(r) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	rx, err := readDateName(r)
+	if err != nil || rx.Name != fs.name {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	qP := r.URL.Query()
+	numbReq, err1 := strconv.Atoi(qP.Get("numbReg"))
+	limit, err2 := strconv.Atoi(qP.Get("strLimit"))
+	offset, err3 := strconv.Atoi(qP.Get("strOffSet"))
+	if err1 != nil || err2 != nil || err3 != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	rows := fs.data[rx.Date]
+	part := make([]DataEl, 0)
+	for i := offset; i < len(rows) && i < offset+limit; i++ {
+		part = append(part, rows[i])
+	}
+
+	tx, _ := json.Marshal(PartDataDB{NumbReq: numbReq, Data: part})
+	w.WriteHeader(http.StatusOK)
+	w.Write(tx)
+}
+
+// Имитация архивных данных.
+//
+// Параметры:
+//
+// n - количество строк.
+func simRows(n int) []DataEl {
+	rows := make([]DataEl, 0, n)
+	for i := 0; i < n; i++ {
+		rows = append(rows, DataEl{
+			Name:      fmt.Sprintf("Dev%d. HR. Тестовая переменная Word", i%3+1),
+			Value:     strconv.Itoa(i),
+			Qual:      "1",
+			TimeStamp: fmt.Sprintf("2025-05-18T03:%02d:%02d.391321+07:00", i/60%60, i%60),
+		})
+	}
+	return rows
+}
+
+// Создание клиента - ошибки
+func Test_NewClient_Error(t *testing.T) {
+
+	argData := []struct {
+		testName string
+		baseURL  string
+		opts     []Option
+		wantErr  string
+	}{
+		{
+			testName: "пустой адрес",
+			baseURL:  "",
+			wantErr:  "client -> пустое значение адреса сервера",
+		},
+		{
+			testName: "неподдерживаемая схема",
+			baseURL:  "ftp://127.0.0.1:21",
+			wantErr:  "client -> неподдерживаемая схема адреса сервера: {ftp}",
+		},
+		{
+			testName: "нет хоста",
+			baseURL:  "https://",
+			wantErr:  "client -> в адресе сервера нет хоста",
+		},
+		{
+			testName: "нет http клиента",
+			baseURL:  "https://127.0.0.1:8443",
+			opts:     []Option{WithHTTPClient(nil)},
+			wantErr:  "client -> нет указателя на http клиент",
+		},
+		{
+			testName: "пустой путь к сертификату",
+			baseURL:  "https://127.0.0.1:8443",
+			opts:     []Option{WithCACert("")},
+			wantErr:  "client -> пустое значение пути к CA-сертификату",
+		},
+		{
+			testName: "нет исполнителей",
+			baseURL:  "https://127.0.0.1:8443",
+			opts:     []Option{WithWorkers(0)},
+			wantErr:  "client -> количество исполнителей должно быть больше нуля",
+		},
+		{
+			testName: "нулевой размер части",
+			baseURL:  "https://127.0.0.1:8443",
+			opts:     []Option{WithPageSize(0)},
+			wantErr:  "client -> размер части должен быть больше нуля",
+		},
+		{
+			testName: "адаптивный размер части, максимум меньше минимума",
+			baseURL:  "https://127.0.0.1:8443",
+			opts:     []Option{WithAdaptivePaging(AdaptivePaging{MinSize: 100, MaxSize: 10, Fast: time.Second})},
+			wantErr:  "client -> максимальный размер части меньше минимального",
+		},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+			_, err := NewClient(tt.baseURL, tt.opts...)
+			rxErr := fmt.Sprintf("%v", err)
+			assert.Equalf(t, tt.wantErr, rxErr, "ожидалась ошибка: {%s}, а принята: {%s}", tt.wantErr, rxErr)
+		})
+	}
+}
+
+// Работа клиента - успешность
+func Test_Client_Success(t *testing.T) {
+
+	date := "2025-05-18"
+	rows := simRows(250)
+	fs := newFakeServer(t, map[string][]DataEl{date: rows})
+
+	client, err := NewClient(fs.URL+"/", WithHTTPClient(fs.Client()))
+	require.NoErrorf(t, err, "создание клиента - ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, fs.URL, client.BaseURL(), "завершающий слэш адреса должен удаляться")
+
+	// Запрос до регистрации
+	_, err = client.Status()
+	require.Error(t, err, "ожидалась ошибка запроса без регистрации")
+	assert.Equal(t, "client -> нет регистрации на сервере", err.Error())
+
+	// Регистрация
+	err = client.Login(fs.name, fs.password)
+	require.NoErrorf(t, err, "регистрация - ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, fs.token, client.User().Token)
+	assert.Equal(t, fs.name, client.User().Name)
+
+	// Статус
+	status, err := client.Status()
+	require.NoErrorf(t, err, "статус - ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, fs.status.TimeStart, status.TimeStart)
+
+	// Количество строк
+	cnt, err := client.CountRows(date)
+	require.NoErrorf(t, err, "количество строк - ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, len(rows), cnt)
+
+	// Выгрузка дня
+	result, err := client.FetchDay(date)
+	require.NoErrorf(t, err, "выгрузка - ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, date, result.Date)
+	assert.Equal(t, len(rows), result.CntStr)
+
+	dataDB := result.DataDB()
+	assert.Equal(t, date, dataDB.StartDate)
+	assert.Equal(t, rows, dataDB.Data, "нет соответствия принятых строк")
+}
+
+// Регистрация клиента - неверные данные
+func Test_Client_LoginError(t *testing.T) {
+
+	fs := newFakeServer(t, nil)
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()))
+	require.NoError(t, err)
+
+	err = client.Login(fs.name, "wrong")
+	require.Error(t, err, "ожидалась ошибка регистрации")
+	assert.True(t, strings.HasPrefix(err.Error(), "login -> "), "ошибка регистрации: {%v}", err)
+	assert.Empty(t, client.User().Token, "токен не должен сохраняться при ошибке")
+}
+
+// Проверка доступности сервера без регистрации
+func Test_Client_Ping(t *testing.T) {
+
+	fs := newFakeServer(t, nil)
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()), WithRetry(fastRetry(5)))
+	require.NoError(t, err)
+
+	// Отказ в авторизации - сервер доступен
+	require.NoErrorf(t, client.Ping(context.Background()), "ожидалось отсутствие ошибки")
+	assert.Equal(t, 1, fs.count(pathStatus))
+
+	// Сервер недоступен, запрос не повторяется
+	fs.Close()
+	err = client.Ping(context.Background())
+	assert.ErrorIs(t, err, ErrNetwork)
+	assert.True(t, strings.HasPrefix(err.Error(), "ping -> "), "ошибка проверки: {%v}", err)
+}
+
+// Выгрузка дня с отменой контекста
+func Test_Client_FetchDayCtx_Canceled(t *testing.T) {
+
+	date := "2025-05-18"
+	fs := newFakeServer(t, map[string][]DataEl{date: simRows(1000)})
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	// Отмена после третьего запроса части
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fs.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == pathPartDataDB && fs.count(pathPartDataDB) == 3 {
+			cancel()
+		}
+		return false
+	})
+
+	result, err := client.FetchDayCtx(ctx, date)
+	require.Error(t, err, "ожидалась ошибка прерывания")
+
+	var errCancel *CanceledError
+	require.Truef(t, errors.As(err, &errCancel), "ожидалась ошибка *CanceledError, а принято: {%v}", err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 10, errCancel.Total)
+	assert.Equal(t, len(result.Pages), errCancel.Received)
+	assert.LessOrEqual(t, len(result.Pages), 3, "после отмены запросы частей не должны выполняться")
+	for i, v := range result.Pages {
+		assert.Equal(t, i, v.NumbReq)
+	}
+}
+
+// Крайний срок запроса
+func Test_Client_RequestTimeout(t *testing.T) {
+
+	fs := newFakeServer(t, nil)
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()), WithRequestTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	release := make(chan struct{})
+	defer close(release)
+	fs.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		return true
+	})
+
+	start := time.Now()
+	_, err = client.Status()
+	require.Error(t, err, "ожидалась ошибка по крайнему сроку")
+	assert.Less(t, time.Since(start), 2*time.Second, "запрос должен прерываться по крайнему сроку")
+}
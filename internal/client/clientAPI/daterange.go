@@ -0,0 +1,144 @@
+package clientapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// Формат даты запросов к серверу
+const dateLayout = "2006-01-02"
+
+// Результат выгрузки архивных данных за диапазон дат
+type RangeResult struct {
+	From string
+	To   string
+	Days []DownloadResult // результаты по дням в порядке дат
+}
+
+// Список дат диапазона включительно. Возвращается список дат в формате YYYY-MM-DD и ошибка.
+//
+// Параметры:
+//
+// from - начальная дата диапазона.
+// to - конечная дата диапазона.
+func DateRange(from, to string) ([]string, error) {
+
+	// Проверка аргументов
+	if from == "" {
+		return nil, withKind(ErrInvalidArg, errors.New("range -> пустое значение начальной даты"))
+	}
+	if to == "" {
+		return nil, withKind(ErrInvalidArg, errors.New("range -> пустое значение конечной даты"))
+	}
+	start, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return nil, withKind(ErrBadDate, errors.New("range -> начальная дата не в формате YYYY-MM-DD"))
+	}
+	end, err := time.Parse(dateLayout, to)
+	if err != nil {
+		return nil, withKind(ErrBadDate, errors.New("range -> конечная дата не в формате YYYY-MM-DD"))
+	}
+	if end.Before(start) {
+		return nil, withKind(ErrInvalidArg, fmt.Errorf("range -> конечная дата {%s} раньше начальной {%s}", to, from))
+	}
+
+	dates := make([]string, 0)
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format(dateLayout))
+	}
+
+	return dates, nil
+}
+
+// Последовательная выгрузка архивных данных по дням диапазона. Итератор возвращает результат
+// каждого дня. При ошибке возвращается частичный результат дня с ошибкой, и перебор завершается.
+//
+// Параметры:
+//
+// ctx - контекст выгрузки.
+// from - начальная дата диапазона.
+// to - конечная дата диапазона.
+func (c *Client) Days(ctx context.Context, from, to string) iter.Seq2[DownloadResult, error] {
+	return func(yield func(DownloadResult, error) bool) {
+
+		dates, err := DateRange(from, to)
+		if err != nil {
+			yield(DownloadResult{}, err)
+			return
+		}
+
+		for _, date := range dates {
+			result, err := c.FetchDayCtx(ctx, date)
+			if !yield(result, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Выгрузка архивных данных за диапазон дат включительно. Возвращается результат по дням и ошибка.
+//
+// Параметры:
+//
+// from - начальная дата диапазона.
+// to - конечная дата диапазона.
+func (c *Client) FetchRange(from, to string) (RangeResult, error) {
+	return c.FetchRangeCtx(context.Background(), from, to)
+}
+
+// Выгрузка архивных данных за диапазон дат с учётом контекста. При ошибке возвращаются
+// результаты уже выгруженных дней, включая частичный результат дня с ошибкой.
+//
+// Параметры:
+//
+// ctx - контекст выгрузки.
+// from - начальная дата диапазона.
+// to - конечная дата диапазона.
+func (c *Client) FetchRangeCtx(ctx context.Context, from, to string) (RangeResult, error) {
+
+	result := RangeResult{
+		From: from,
+		To:   to,
+		Days: make([]DownloadResult, 0),
+	}
+
+	for day, err := range c.Days(ctx, from, to) {
+		if day.Date != "" {
+			result.Days = append(result.Days, day)
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// Количество строк по ответам /cntstr за все дни диапазона.
+func (r RangeResult) CntStr() int {
+	cnt := 0
+	for _, v := range r.Days {
+		cnt += v.CntStr
+	}
+	return cnt
+}
+
+// Сведение строк всех дней диапазона в одну структуру для сохранения.
+func (r RangeResult) DataDB() RxDataDB {
+
+	data := RxDataDB{
+		StartDate: r.From,
+		EndDate:   r.To,
+		Data:      make([]DataEl, 0, r.CntStr()),
+	}
+	for _, day := range r.Days {
+		for _, v := range day.Pages {
+			data.Data = append(data.Data, v.Data...)
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,120 @@
+package clientapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Список дат диапазона - успешность
+func Test_DateRange_Success(t *testing.T) {
+
+	dates, err := DateRange("2025-02-27", "2025-03-02")
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, []string{"2025-02-27", "2025-02-28", "2025-03-01", "2025-03-02"}, dates)
+
+	dates, err = DateRange("2025-05-18", "2025-05-18")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2025-05-18"}, dates)
+}
+
+// Список дат диапазона - ошибки
+func Test_DateRange_Error(t *testing.T) {
+
+	argData := []struct {
+		testName string
+		from     string
+		to       string
+		wantErr  string
+		wantKind error
+	}{
+		{
+			testName: "пустая начальная дата",
+			from:     "",
+			to:       "2025-05-18",
+			wantErr:  "range -> пустое значение начальной даты",
+			wantKind: ErrInvalidArg,
+		},
+		{
+			testName: "пустая конечная дата",
+			from:     "2025-05-18",
+			to:       "",
+			wantErr:  "range -> пустое значение конечной даты",
+			wantKind: ErrInvalidArg,
+		},
+		{
+			testName: "начальная дата не в формате",
+			from:     "18.05.2025",
+			to:       "2025-05-18",
+			wantErr:  "range -> начальная дата не в формате YYYY-MM-DD",
+			wantKind: ErrBadDate,
+		},
+		{
+			testName: "конечная дата не в формате",
+			from:     "2025-05-18",
+			to:       "2025-5-19",
+			wantErr:  "range -> конечная дата не в формате YYYY-MM-DD",
+			wantKind: ErrBadDate,
+		},
+		{
+			testName: "конечная дата раньше начальной",
+			from:     "2025-05-18",
+			to:       "2025-05-17",
+			wantErr:  "range -> конечная дата {2025-05-17} раньше начальной {2025-05-18}",
+			wantKind: ErrInvalidArg,
+		},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+			_, err := DateRange(tt.from, tt.to)
+			rxErr := fmt.Sprintf("%v", err)
+			assert.Equalf(t, tt.wantErr, rxErr, "ожидалась ошибка: {%s}, а принята: {%s}", tt.wantErr, rxErr)
+			assert.ErrorIs(t, err, tt.wantKind)
+		})
+	}
+}
+
+// Выгрузка диапазона дат
+func Test_Client_FetchRange(t *testing.T) {
+
+	data := map[string][]DataEl{
+		"2025-05-17": simRows(150),
+		"2025-05-19": simRows(320),
+	}
+	fs := newFakeServer(t, data)
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	result, err := client.FetchRange("2025-05-17", "2025-05-19")
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	require.Len(t, result.Days, 3)
+	assert.Equal(t, "2025-05-18", result.Days[1].Date)
+	assert.Equal(t, 0, result.Days[1].CntStr)
+	assert.Equal(t, 470, result.CntStr())
+
+	dataDB := result.DataDB()
+	assert.Equal(t, "2025-05-17", dataDB.StartDate)
+	assert.Equal(t, "2025-05-19", dataDB.EndDate)
+	assert.Equal(t, append(append([]DataEl{}, data["2025-05-17"]...), data["2025-05-19"]...), dataDB.Data)
+
+	// Прерывание перебора дней
+	cnt := 0
+	for day, err := range client.Days(context.Background(), "2025-05-17", "2025-05-19") {
+		require.NoError(t, err)
+		assert.Equal(t, "2025-05-17", day.Date)
+		cnt++
+		break
+	}
+	assert.Equal(t, 1, cnt)
+	assert.Equal(t, 4, fs.count(pathCntStr), "после прерывания следующие дни не запрашиваются")
+
+	// Ошибка диапазона
+	_, err = client.FetchRange("2025-05-19", "2025-05-17")
+	assert.ErrorIs(t, err, ErrInvalidArg)
+}
@@ -0,0 +1,164 @@
+package clientapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Категории ошибок клиента для проверки через errors.Is
+var (
+	ErrInvalidArg   = errors.New("некорректное значение аргумента")
+	ErrBadDate      = errors.New("дата не в формате YYYY-MM-DD")
+	ErrNoSession    = errors.New("нет регистрации на сервере")
+	ErrUnauthorized = errors.New("нет авторизации на сервере")
+	ErrServer       = errors.New("ошибка сервера")
+	ErrNetwork      = errors.New("ошибка сети")
+	ErrCertificate  = errors.New("ошибка сертификата сервера")
+	ErrDecode       = errors.New("ошибка обработки ответа сервера")
+)
+
+// Ошибка прерывания выгрузки по отмене или крайнему сроку контекста.
+// Вместе с ошибкой возвращаются части, принятые до прерывания.
+type CanceledError struct {
+	Received int   // количество принятых частей
+	Total    int   // общее количество частей
+	Err      error // причина прерывания (context.Canceled или context.DeadlineExceeded)
+}
+
+// Создание ошибки прерывания выгрузки.
+//
+// Параметры:
+//
+// ctx - контекст выгрузки.
+// received - количество принятых частей.
+// total - общее количество частей.
+func newCanceledError(ctx context.Context, received, total int) *CanceledError {
+	return &CanceledError{
+		Received: received,
+		Total:    total,
+		Err:      context.Cause(ctx),
+	}
+}
+
+func (e *CanceledError) Error() string {
+	return fmt.Sprintf("queReq -> выгрузка прервана, принято частей {%d} из {%d}: {%v}", e.Received, e.Total, e.Err)
+}
+
+func (e *CanceledError) Unwrap() error {
+	return e.Err
+}
+
+// Ошибка несоответствия количества принятых строк количеству, которое сообщил сервер.
+type CountMismatchError struct {
+	Expected int // количество строк по ответу /cntstr
+	Actual   int // количество принятых строк
+}
+
+func (e *CountMismatchError) Error() string {
+	return fmt.Sprintf("queReq -> нет соответствия количества строк: ожидалось {%d}, принято {%d}", e.Expected, e.Actual)
+}
+
+// Ошибка кода ответа сервера, отличного от 200. Сохраняет путь запроса, код и тело ответа.
+// errors.Is сопоставляет коды 401/403 с ErrUnauthorized, коды 5xx - с ErrServer.
+type HTTPError struct {
+	Endpoint string // путь запроса
+	Status   int    // код ответа сервера
+	Body     string // тело ответа (не более maxErrBody байт)
+}
+
+// Максимальный размер тела ответа, сохраняемого в ошибке
+const maxErrBody = 1024
+
+// Создание ошибки кода ответа с чтением тела ответа.
+//
+// Параметры:
+//
+// endpoint - путь запроса.
+// resp - ответ сервера.
+func newHTTPError(endpoint string, resp *http.Response) *HTTPError {
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrBody))
+
+	return &HTTPError{
+		Endpoint: endpoint,
+		Status:   resp.StatusCode,
+		Body:     strings.TrimSpace(string(body)),
+	}
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("запрос {%s}, код ответа {%d}, тело ответа {%s}", e.Endpoint, e.Status, e.Body)
+}
+
+func (e *HTTPError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.Status == http.StatusUnauthorized || e.Status == http.StatusForbidden
+	case ErrServer:
+		return e.Status >= 500
+	}
+	return false
+}
+
+// Категория ошибки выполнения запроса: проверка сертификата сервера или сетевой сбой.
+// Ошибка сертификата означает неверные настройки клиента, а не недоступность сервера.
+//
+// Параметры:
+//
+// err - ошибка выполнения запроса.
+func requestKind(err error) error {
+
+	if isCertError(err) {
+		return ErrCertificate
+	}
+	return ErrNetwork
+}
+
+// Ошибка с признаком категории. Текст исходной ошибки не меняется, а errors.Is находит
+// как категорию (ErrInvalidArg, ErrNetwork и т.д.), так и исходную причину.
+type kindError struct {
+	kind error
+	err  error
+}
+
+// Присвоение ошибке категории.
+//
+// Параметры:
+//
+// kind - категория ошибки.
+// err - исходная ошибка.
+func withKind(kind, err error) error {
+	return &kindError{kind: kind, err: err}
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// Проверка ошибки на временный сбой сервера: превышение времени ожидания или код ответа 5xx.
+//
+// Параметры:
+//
+// err - ошибка запроса.
+func isOverload(err error) bool {
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, ErrServer)
+}
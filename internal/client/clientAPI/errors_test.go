@@ -0,0 +1,96 @@
+package clientapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Категории ошибок клиента
+func Test_Errors_Kinds(t *testing.T) {
+
+	date := "2025-05-18"
+	fs := newFakeServer(t, map[string][]DataEl{date: simRows(300)})
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()))
+	require.NoError(t, err)
+
+	// Запрос без регистрации
+	_, err = client.Status()
+	assert.ErrorIs(t, err, ErrNoSession)
+
+	// Неверный пароль
+	err = client.Login(fs.name, "wrong")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.NotErrorIs(t, err, ErrServer)
+
+	var errHTTP *HTTPError
+	require.Truef(t, errors.As(err, &errHTTP), "ожидалась ошибка *HTTPError, а принято: {%v}", err)
+	assert.Equal(t, pathLogin, errHTTP.Endpoint)
+	assert.Equal(t, http.StatusUnauthorized, errHTTP.Status)
+	assert.Equal(t, http.StatusText(http.StatusUnauthorized), errHTTP.Body)
+
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	// Неверная дата и пустой аргумент
+	_, err = client.CountRows("18-05-2025")
+	assert.ErrorIs(t, err, ErrBadDate)
+	_, err = client.CountRows("")
+	assert.ErrorIs(t, err, ErrInvalidArg)
+	assert.Equal(t, "req-cntStr -> пустое значение даты", err.Error(), "текст ошибки аргумента не меняется")
+
+	// Ошибка сервера при выгрузке части
+	fs.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == pathPartDataDB {
+			http.Error(w, "database is locked", http.StatusInternalServerError)
+			return true
+		}
+		return false
+	})
+	_, err = client.FetchDay(date)
+	assert.ErrorIs(t, err, ErrServer)
+	require.Truef(t, errors.As(err, &errHTTP), "ожидалась ошибка *HTTPError, а принято: {%v}", err)
+	assert.Equal(t, pathPartDataDB, errHTTP.Endpoint)
+	assert.Equal(t, "database is locked", errHTTP.Body)
+	assert.Contains(t, err.Error(), "запрос {/partdatadb}, код ответа {500}, тело ответа {database is locked}", "путь запроса в тексте ошибки")
+
+	// Ответ не в формате JSON
+	fs.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("<html>"))
+		return true
+	})
+	_, err = client.Status()
+	assert.ErrorIs(t, err, ErrDecode)
+
+	// Сервер недоступен
+	fs.Close()
+	_, err = client.Status()
+	assert.ErrorIs(t, err, ErrNetwork)
+	assert.NotErrorIs(t, err, ErrUnauthorized)
+}
+
+// Ошибка сертификата сервера не считается недоступностью сервера
+func Test_Errors_Certificate(t *testing.T) {
+
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+
+	// Клиент без публичного ключа сервера
+	client, err := NewClient(srv.URL, WithRetry(fastRetry(3)))
+	require.NoError(t, err)
+
+	err = client.Login("test", "123")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrCertificate)
+	assert.NotErrorIs(t, err, ErrNetwork)
+
+	err = client.Ping(context.Background())
+	assert.ErrorIs(t, err, ErrCertificate)
+	assert.NotErrorIs(t, err, ErrNetwork)
+}
@@ -0,0 +1,280 @@
+package clientapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type (
+	// Запрос одной части архивных данных
+	pageFunc func(ctx context.Context, numbReq, strLimit, strOffSet int) (PartDataDB, error)
+
+	// Параметры очереди запросов
+	queueCfg struct {
+		pageSize int                   // количество строк в одном запросе
+		workers  int                   // количество параллельных запросов (1 - последовательно)
+		pause    time.Duration         // пауза между запросами в последовательном режиме
+		adaptive *AdaptivePaging       // адаптивный размер части (только последовательно)
+		progress func(done, total int) // отображение хода выгрузки в строках
+	}
+
+	// Параметры адаптивного размера части. Размер удваивается, пока ответы приходят быстрее Fast,
+	// и уменьшается вдвое после превышения времени ожидания или ответа 5xx с повтором той же части.
+	AdaptivePaging struct {
+		MinSize int           // минимальный размер части
+		MaxSize int           // максимальный размер части
+		Fast    time.Duration // ответ быстрее этого значения увеличивает размер части
+	}
+
+	// Результат запроса части в параллельном режиме
+	pageResult struct {
+		numbReq int
+		part    PartDataDB
+		err     error
+	}
+)
+
+// Выполнение очереди запросов частей. Части передаются в emit строго по порядку NumbReq.
+// Возвращаются размеры переданных частей и ошибка.
+//
+// Параметры:
+//
+// ctx - контекст выгрузки.
+// cntStr - количество запрашиваемых строк.
+// cfg - параметры очереди.
+// fetch - запрос одной части.
+// emit - приём очередной части.
+func runQueue(ctx context.Context, cntStr int, cfg queueCfg, fetch pageFunc, emit func(PartDataDB) error) ([]int, error) {
+
+	if cfg.pageSize <= 0 {
+		cfg.pageSize = pageSize
+	}
+	if cfg.adaptive != nil {
+		return runQueueAdaptive(ctx, cntStr, cfg, fetch, emit)
+	}
+
+	// Вычисление количества необходимых запросов (с округлением вверх, последняя часть может быть неполной)
+	iter := (cntStr + cfg.pageSize - 1) / cfg.pageSize
+
+	if cfg.workers > 1 && iter > 1 {
+		return runQueueParallel(ctx, cntStr, iter, cfg, fetch, emit)
+	}
+	return runQueueSeq(ctx, cntStr, iter, cfg, fetch, emit)
+}
+
+// Границы части: количество строк и смещение.
+func pageBounds(cntStr, size, numbReq int) (strLimit, strOffSet int) {
+	strOffSet = size * numbReq
+	strLimit = min(size, cntStr-strOffSet)
+	return strLimit, strOffSet
+}
+
+// Последовательная очередь запросов с паузой между запросами.
+func runQueueSeq(ctx context.Context, cntStr, iter int, cfg queueCfg, fetch pageFunc, emit func(PartDataDB) error) ([]int, error) {
+
+	sizes := make([]int, 0, iter)
+
+	for i := 0; i < iter; i++ {
+
+		// Проверка отмены между запросами
+		if ctx.Err() != nil {
+			return sizes, newCanceledError(ctx, i, iter)
+		}
+
+		strLimit, strOffSet := pageBounds(cntStr, cfg.pageSize, i)
+
+		rxData, err := fetch(ctx, i, strLimit, strOffSet)
+		if err != nil {
+			if ctx.Err() != nil {
+				return sizes, newCanceledError(ctx, i, iter)
+			}
+			return sizes, fmt.Errorf("queReq -> ошибка при выполнении запроса на итерации {%d}, {%w}", i, err)
+		}
+
+		err = emit(rxData)
+		if err != nil {
+			return sizes, err
+		}
+		sizes = append(sizes, strLimit)
+		if cfg.progress != nil {
+			cfg.progress(strOffSet+strLimit, cntStr)
+		}
+
+		// установка небольшой паузы между очередным запросом
+		if cfg.pause > 0 && i < iter-1 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(cfg.pause):
+			}
+		}
+	}
+
+	return sizes, nil
+}
+
+// Параллельная очередь запросов с ограниченным количеством исполнителей.
+// Первая ошибка останавливает остальных исполнителей. Опережение выдачи ограничено, чтобы
+// при задержке одной части в памяти не накапливались последующие.
+func runQueueParallel(ctx context.Context, cntStr, iter int, cfg queueCfg, fetch pageFunc, emit func(PartDataDB) error) ([]int, error) {
+
+	workCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	results := make(chan pageResult, cfg.workers)
+	window := make(chan struct{}, 2*cfg.workers)
+
+	// Раздача номеров частей
+	go func() {
+		defer close(jobs)
+		for i := 0; i < iter; i++ {
+			select {
+			case window <- struct{}{}:
+			case <-workCtx.Done():
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-workCtx.Done():
+				return
+			}
+		}
+	}()
+
+	// Исполнители
+	var wg sync.WaitGroup
+	for w := 0; w < min(cfg.workers, iter); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				strLimit, strOffSet := pageBounds(cntStr, cfg.pageSize, i)
+				part, err := fetch(workCtx, i, strLimit, strOffSet)
+				results <- pageResult{numbReq: i, part: part, err: err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Сборка частей по порядку
+	var firstErr error
+	pending := make(map[int]PartDataDB)
+	sizes := make([]int, 0, iter)
+	next := 0
+
+	for r := range results {
+		if firstErr != nil {
+			continue
+		}
+		if r.err != nil {
+			firstErr = fmt.Errorf("queReq -> ошибка при выполнении запроса на итерации {%d}, {%w}", r.numbReq, r.err)
+			cancel()
+			continue
+		}
+
+		pending[r.numbReq] = r.part
+		for {
+			part, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+
+			err := emit(part)
+			if err != nil {
+				firstErr = err
+				cancel()
+				break
+			}
+			strLimit, strOffSet := pageBounds(cntStr, cfg.pageSize, next)
+			sizes = append(sizes, strLimit)
+			next++
+			<-window
+
+			if cfg.progress != nil {
+				cfg.progress(strOffSet+strLimit, cntStr)
+			}
+		}
+	}
+
+	if ctx.Err() != nil && next < iter {
+		return sizes, newCanceledError(ctx, next, iter)
+	}
+	if firstErr != nil {
+		return sizes, firstErr
+	}
+
+	return sizes, nil
+}
+
+// Последовательная очередь запросов с адаптивным размером части.
+// После превышения времени ожидания или ответа 5xx та же часть запрашивается повторно с меньшим размером.
+func runQueueAdaptive(ctx context.Context, cntStr int, cfg queueCfg, fetch pageFunc, emit func(PartDataDB) error) ([]int, error) {
+
+	ad := cfg.adaptive
+	size := min(max(cfg.pageSize, ad.MinSize), ad.MaxSize)
+
+	sizes := make([]int, 0)
+	strOffSet := 0
+
+	// Оценка общего количества частей для ошибки прерывания
+	estimate := func() int {
+		return len(sizes) + (cntStr-strOffSet+size-1)/size
+	}
+
+	for strOffSet < cntStr {
+
+		// Проверка отмены между запросами
+		if ctx.Err() != nil {
+			return sizes, newCanceledError(ctx, len(sizes), estimate())
+		}
+
+		numbReq := len(sizes)
+		strLimit := min(size, cntStr-strOffSet)
+
+		start := time.Now()
+		rxData, err := fetch(ctx, numbReq, strLimit, strOffSet)
+		elapsed := time.Since(start)
+		if err != nil {
+			if ctx.Err() != nil {
+				return sizes, newCanceledError(ctx, len(sizes), estimate())
+			}
+			if isOverload(err) && size > ad.MinSize {
+				size = max(size/2, ad.MinSize)
+				continue
+			}
+			return sizes, fmt.Errorf("queReq -> ошибка при выполнении запроса на итерации {%d}, размер части {%d}, {%w}", numbReq, strLimit, err)
+		}
+
+		err = emit(rxData)
+		if err != nil {
+			return sizes, err
+		}
+		sizes = append(sizes, strLimit)
+		strOffSet += strLimit
+
+		if cfg.progress != nil {
+			cfg.progress(strOffSet, cntStr)
+		}
+
+		// Увеличение размера части при быстром ответе
+		if elapsed < ad.Fast {
+			size = min(size*2, ad.MaxSize)
+		}
+
+		// установка небольшой паузы между очередным запросом
+		if cfg.pause > 0 && strOffSet < cntStr {
+			select {
+			case <-ctx.Done():
+			case <-time.After(cfg.pause):
+			}
+		}
+	}
+
+	return sizes, nil
+}
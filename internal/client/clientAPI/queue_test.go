@@ -0,0 +1,293 @@
+package clientapi
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Имитация запроса части по набору строк со случайной задержкой.
+//
+// Параметры:
+//
+// rows - архивные данные.
+// calls - счётчик запросов.
+func simFetch(rows []DataEl, calls *atomic.Int32) pageFunc {
+	return func(ctx context.Context, numbReq, strLimit, strOffSet int) (PartDataDB, error) {
+		calls.Add(1)
+		select {
+		case <-ctx.Done():
+			return PartDataDB{}, ctx.Err()
+		case <-time.After(time.Duration(rand.Intn(3)) * time.Millisecond):
+		}
+		return PartDataDB{NumbReq: numbReq, Data: rows[strOffSet : strOffSet+strLimit]}, nil
+	}
+}
+
+// Очередь запросов - сборка частей по порядку
+func Test_runQueue_Order(t *testing.T) {
+
+	rows := simRows(1234)
+
+	argData := []struct {
+		testName string
+		workers  int
+		pageSize int
+	}{
+		{testName: "последовательно", workers: 1, pageSize: 100},
+		{testName: "4 исполнителя", workers: 4, pageSize: 100},
+		{testName: "16 исполнителей, малая часть", workers: 16, pageSize: 7},
+		{testName: "исполнителей больше частей", workers: 64, pageSize: 500},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+
+			var calls atomic.Int32
+			got := make([]DataEl, 0, len(rows))
+			nextNumb := 0
+
+			cfg := queueCfg{pageSize: tt.pageSize, workers: tt.workers}
+			sizes, err := runQueue(context.Background(), len(rows), cfg, simFetch(rows, &calls), func(part PartDataDB) error {
+				assert.Equal(t, nextNumb, part.NumbReq, "части должны передаваться по порядку")
+				nextNumb++
+				got = append(got, part.Data...)
+				return nil
+			})
+			require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+
+			wantPages := (len(rows) + tt.pageSize - 1) / tt.pageSize
+			assert.Len(t, sizes, wantPages)
+			assert.Equal(t, int32(wantPages), calls.Load(), "каждая часть запрашивается один раз")
+			assert.Equal(t, rows, got)
+		})
+	}
+}
+
+// Очередь запросов - первая ошибка останавливает исполнителей
+func Test_runQueue_ParallelError(t *testing.T) {
+
+	rows := simRows(10000)
+	var calls atomic.Int32
+	fetch := simFetch(rows, &calls)
+
+	failing := func(ctx context.Context, numbReq, strLimit, strOffSet int) (PartDataDB, error) {
+		if numbReq == 5 {
+			return PartDataDB{}, errors.New("сбой запроса")
+		}
+		return fetch(ctx, numbReq, strLimit, strOffSet)
+	}
+
+	emitted := 0
+	cfg := queueCfg{pageSize: 10, workers: 4}
+	sizes, err := runQueue(context.Background(), len(rows), cfg, failing, func(part PartDataDB) error {
+		emitted++
+		return nil
+	})
+	require.Error(t, err, "ожидалась ошибка очереди")
+	assert.Contains(t, err.Error(), "на итерации {5}")
+	assert.Len(t, sizes, emitted)
+	assert.LessOrEqual(t, emitted, 5, "после ошибки части за ней не передаются")
+	assert.Less(t, int(calls.Load()), 100, "после ошибки исполнители должны остановиться")
+}
+
+// Очередь запросов - отмена контекста в параллельном режиме
+func Test_runQueue_ParallelCanceled(t *testing.T) {
+
+	rows := simRows(10000)
+	var calls atomic.Int32
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := queueCfg{pageSize: 10, workers: 4}
+	sizes, err := runQueue(ctx, len(rows), cfg, simFetch(rows, &calls), func(part PartDataDB) error {
+		if part.NumbReq == 20 {
+			cancel()
+		}
+		return nil
+	})
+
+	var errCancel *CanceledError
+	require.Truef(t, errors.As(err, &errCancel), "ожидалась ошибка *CanceledError, а принято: {%v}", err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, len(sizes), errCancel.Received)
+	assert.Equal(t, 1000, errCancel.Total)
+	assert.GreaterOrEqual(t, len(sizes), 21, "переданные до отмены части учитываются")
+}
+
+// Выгрузка дня клиентом в параллельном режиме
+func Test_Client_FetchDayParallel(t *testing.T) {
+
+	date := "2025-05-18"
+	rows := simRows(2345)
+	fs := newFakeServer(t, map[string][]DataEl{date: rows})
+
+	var progress atomic.Int32
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()), WithWorkers(8), WithProgress(func(done, total int) {
+		progress.Store(int32(done*1000 + total))
+	}))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	result, err := client.FetchDay(date)
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, rows, result.DataDB().Data)
+	assert.Equal(t, 24, fs.count(pathPartDataDB))
+	assert.Equal(t, int32(2345*1000+2345), progress.Load(), "последний вызов хода выгрузки - все строки")
+	assert.Len(t, result.PageSizes, 24)
+	assert.Equal(t, 45, result.PageSizes[23], "последняя часть запрашивается на оставшиеся строки")
+}
+
+// Очередь запросов функции выгрузки без клиента в параллельном режиме
+func Test_QueReqPartDataDB_Workers(t *testing.T) {
+
+	date := "2025-05-18"
+	rows := simRows(2345)
+	fs := newFakeServer(t, map[string][]DataEl{date: rows})
+
+	// Наибольшее количество одновременных запросов частей
+	var active, peak atomic.Int32
+	fs.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == pathPartDataDB {
+			n := active.Add(1)
+			defer active.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		return false
+	})
+
+	parts, err := QueReqPartDataDB(date, fs.token, fs.name, fs.URL+pathPartDataDB, len(rows), fs.Client(), WithWorkers(4), WithPageSize(200))
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	require.Len(t, parts, 12)
+
+	got := make([]DataEl, 0, len(rows))
+	for i, part := range parts {
+		assert.Equal(t, i, part.NumbReq, "части собираются по порядку")
+		got = append(got, part.Data...)
+	}
+	assert.Equal(t, rows, got)
+	assert.Greater(t, peak.Load(), int32(1), "части запрашиваются параллельно")
+	assert.LessOrEqual(t, peak.Load(), int32(4), "не больше заданного количества исполнителей")
+
+	_, err = QueReqPartDataDB(date, fs.token, fs.name, fs.URL+pathPartDataDB, len(rows), fs.Client(), WithWorkers(0))
+	assert.ErrorIs(t, err, ErrInvalidArg)
+}
+
+// Очередь запросов - адаптивный размер части
+func Test_runQueue_Adaptive(t *testing.T) {
+
+	rows := simRows(5000)
+	var calls atomic.Int32
+	fetch := simFetch(rows, &calls)
+
+	// Части больше 400 строк отвечают кодом 503
+	overloaded := func(ctx context.Context, numbReq, strLimit, strOffSet int) (PartDataDB, error) {
+		if strLimit > 400 {
+			return PartDataDB{}, &HTTPError{Endpoint: pathPartDataDB, Status: http.StatusServiceUnavailable}
+		}
+		return fetch(ctx, numbReq, strLimit, strOffSet)
+	}
+
+	got := make([]DataEl, 0, len(rows))
+	cfg := queueCfg{
+		pageSize: 100,
+		adaptive: &AdaptivePaging{MinSize: 50, MaxSize: 1000, Fast: time.Second},
+	}
+	sizes, err := runQueue(context.Background(), len(rows), cfg, overloaded, func(part PartDataDB) error {
+		got = append(got, part.Data...)
+		return nil
+	})
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, rows, got)
+
+	// Рост 100 -> 200 -> 400, попытка 800 -> 503 -> 400
+	require.GreaterOrEqual(t, len(sizes), 4)
+	assert.Equal(t, []int{100, 200, 400, 400}, sizes[:4])
+	for _, v := range sizes {
+		assert.LessOrEqual(t, v, 400)
+	}
+
+	// Ошибка на минимальном размере части не повторяется
+	failing := func(ctx context.Context, numbReq, strLimit, strOffSet int) (PartDataDB, error) {
+		return PartDataDB{}, &HTTPError{Endpoint: pathPartDataDB, Status: http.StatusInternalServerError}
+	}
+	cfg.pageSize = 50
+	_, err = runQueue(context.Background(), len(rows), cfg, failing, func(part PartDataDB) error { return nil })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "размер части {50}")
+}
+
+// Адаптивная выгрузка клиентом с повторами - перегрузка сервера уменьшает часть без повторов того же размера
+func Test_Client_FetchDayAdaptiveRetry(t *testing.T) {
+
+	date := "2025-05-18"
+	rows := simRows(3000)
+	fs := newFakeServer(t, map[string][]DataEl{date: rows})
+
+	// Части больше 400 строк отвечают кодом 503
+	var mu sync.Mutex
+	attempts := make(map[[2]int]int)
+	fs.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != pathPartDataDB {
+			return false
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("strLimit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("strOffSet"))
+		mu.Lock()
+		attempts[[2]int{offset, limit}]++
+		mu.Unlock()
+		if limit > 400 {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return true
+		}
+		return false
+	})
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()), WithRetry(fastRetry(5)),
+		WithAdaptivePaging(AdaptivePaging{MinSize: 100, MaxSize: 1600, Fast: time.Minute}))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	result, err := client.FetchDay(date)
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, rows, result.DataDB().Data)
+
+	for part, n := range attempts {
+		if part[1] > 400 {
+			assert.Equalf(t, 1, n, "часть {%d} строк со смещением {%d} запрошена повторно до уменьшения", part[1], part[0])
+		}
+	}
+}
+
+// Выгрузка дня клиентом с заданным размером части
+func Test_Client_FetchDayPageSize(t *testing.T) {
+
+	date := "2025-05-18"
+	rows := simRows(1001)
+	fs := newFakeServer(t, map[string][]DataEl{date: rows})
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()), WithPageSize(250))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	result, err := client.FetchDay(date)
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, rows, result.DataDB().Data)
+	assert.Equal(t, []int{250, 250, 250, 250, 1}, result.PageSizes)
+}
@@ -0,0 +1,166 @@
+package clientapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Политика повторов запросов при временных сбоях связи и сервера.
+// Повтор выполняется для каждого отдельного запроса, уже принятые части повторно не запрашиваются.
+type RetryPolicy struct {
+	MaxAttempts int           // максимальное количество попыток (1 - без повторов)
+	BaseDelay   time.Duration // задержка перед первым повтором, далее удваивается
+	MaxDelay    time.Duration // максимальная задержка между попытками (0 - без ограничения)
+	Jitter      float64       // доля случайного разброса задержки (0..1)
+	RetryStatus []int         // коды ответа сервера, при которых выполняется повтор
+	RetryNetErr bool          // повтор при сетевых ошибках и превышении времени ожидания
+
+	skipOverload bool // без повтора при перегрузке сервера: размер части уменьшает адаптивная очередь
+}
+
+// Политика повторов по умолчанию для нестабильной WiFi сети.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.2,
+		RetryStatus: []int{
+			http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryNetErr: true,
+	}
+}
+
+// Проверка ошибки на возможность повтора запроса.
+//
+// Параметры:
+//
+// err - ошибка запроса.
+func (p RetryPolicy) retryable(err error) bool {
+
+	if p.skipOverload && isOverload(err) {
+		return false
+	}
+
+	var errHTTP *HTTPError
+	if errors.As(err, &errHTTP) {
+		return slices.Contains(p.RetryStatus, errHTTP.Status)
+	}
+
+	if !p.RetryNetErr {
+		return false
+	}
+	return isNetError(err)
+}
+
+// Задержка перед повтором с экспоненциальным ростом и случайным разбросом.
+//
+// Параметры:
+//
+// attempt - номер выполненной попытки, начиная с 1.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay) && d < math.MaxInt64/2; i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		d = time.Duration(float64(d) * (1 + p.Jitter*(2*rand.Float64()-1)))
+	}
+
+	return max(d, 0)
+}
+
+// Проверка ошибки на сетевой сбой: разрыв соединения или превышение времени ожидания.
+// Ошибки проверки сертификата сервера сетевым сбоем не считаются.
+//
+// Параметры:
+//
+// err - ошибка запроса.
+func isNetError(err error) bool {
+
+	if isCertError(err) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// Проверка ошибки на ошибку сертификата сервера.
+func isCertError(err error) bool {
+
+	var errVerify *tls.CertificateVerificationError
+	var errAuthority x509.UnknownAuthorityError
+	var errHost x509.HostnameError
+	var errInvalid x509.CertificateInvalidError
+
+	return errors.As(err, &errVerify) || errors.As(err, &errAuthority) ||
+		errors.As(err, &errHost) || errors.As(err, &errInvalid)
+}
+
+// Выполнение запроса к серверу с ограничением времени каждой попытки и повторами по политике клиента.
+// Возвращается ошибка последней попытки.
+//
+// Параметры:
+//
+// ctx - контекст запроса.
+// fn - запрос к серверу.
+func (c *Client) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	return c.callRetry(ctx, c.retry, fn)
+}
+
+// Выполнение запроса к серверу с повторами по заданной политике. Возвращается ошибка последней попытки.
+//
+// Параметры:
+//
+// ctx - контекст запроса.
+// retry - политика повторов.
+// fn - запрос к серверу.
+func (c *Client) callRetry(ctx context.Context, retry RetryPolicy, fn func(ctx context.Context) error) error {
+
+	attempts := max(retry.MaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+
+		reqCtx, cancel := c.reqCtx(ctx)
+		err := fn(reqCtx)
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || attempt >= attempts || !retry.retryable(err) {
+			return err
+		}
+
+		// Ожидание перед повтором
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(retry.delay(attempt)):
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package clientapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Политика повторов без задержек для тестов
+func fastRetry(attempts int) RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.MaxAttempts = attempts
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 2 * time.Millisecond
+	return p
+}
+
+// Задержка повтора
+func Test_RetryPolicy_delay(t *testing.T) {
+
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	assert.Equal(t, 100*time.Millisecond, p.delay(1))
+	assert.Equal(t, 200*time.Millisecond, p.delay(2))
+	assert.Equal(t, 800*time.Millisecond, p.delay(4))
+	assert.Equal(t, time.Second, p.delay(5), "задержка ограничивается MaxDelay")
+	assert.Equal(t, time.Second, p.delay(100), "задержка ограничивается MaxDelay")
+
+	p.MaxDelay = 0
+	assert.Equal(t, 1600*time.Millisecond, p.delay(5), "без MaxDelay задержка удваивается без ограничения")
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := p.delay(1)
+		assert.GreaterOrEqual(t, d, 50*time.Millisecond)
+		assert.LessOrEqual(t, d, 150*time.Millisecond)
+	}
+}
+
+// Выгрузка дня с временными сбоями сервера и связи
+func Test_Client_RetryTransient(t *testing.T) {
+
+	date := "2025-05-18"
+	rows := simRows(1000)
+	fs := newFakeServer(t, map[string][]DataEl{date: rows})
+
+	// Каждая часть: первая попытка - 503, вторая - разрыв соединения, третья - успешно
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	fs.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != pathPartDataDB {
+			return false
+		}
+		key := r.URL.Query().Get("strOffSet")
+
+		mu.Lock()
+		attempts[key]++
+		n := attempts[key]
+		mu.Unlock()
+
+		switch n {
+		case 1:
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return true
+		case 2:
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return true
+		}
+		return false
+	})
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()), WithRetry(fastRetry(3)), WithWorkers(4))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	result, err := client.FetchDay(date)
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, rows, result.DataDB().Data)
+
+	// Каждая часть запрашивалась ровно три раза, принятые части повторно не запрашивались
+	assert.Len(t, attempts, 10)
+	for i := 0; i < 10; i++ {
+		assert.Equalf(t, 3, attempts[strconv.Itoa(i*100)], "количество попыток части {%d}", i)
+	}
+}
+
+// Повторы - исчерпание попыток и коды без повтора
+func Test_Client_RetryNotRetryable(t *testing.T) {
+
+	fs := newFakeServer(t, nil)
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()), WithRetry(fastRetry(4)))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	argData := []struct {
+		testName  string
+		code      int
+		wantCalls int
+	}{
+		{testName: "503 - все попытки", code: http.StatusServiceUnavailable, wantCalls: 4},
+		{testName: "400 - без повтора", code: http.StatusBadRequest, wantCalls: 1},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+
+			calls := 0
+			fs.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+				calls++
+				http.Error(w, http.StatusText(tt.code), tt.code)
+				return true
+			})
+
+			_, err := client.Status()
+			require.Error(t, err)
+			assert.Equal(t, fmt.Sprintf("req-status -> нет успешности запроса: запрос {/status}, код ответа {%d}, тело ответа {%s}", tt.code, http.StatusText(tt.code)), err.Error())
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+// Повторы - отмена контекста во время ожидания
+func Test_Client_RetryCanceled(t *testing.T) {
+
+	fs := newFakeServer(t, nil)
+
+	p := fastRetry(10)
+	p.BaseDelay = time.Hour
+	p.MaxDelay = time.Hour
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()), WithRetry(p))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	fs.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return true
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.StatusCtx(ctx)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second, "ожидание повтора должно прерываться контекстом")
+}
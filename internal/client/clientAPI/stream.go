@@ -0,0 +1,121 @@
+package clientapi
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// Признак остановки выгрузки получателем потока
+var errStopStream = errors.New("stream -> остановлено получателем")
+
+// Выгрузка архивных данных за день с передачей каждой части в fn по порядку NumbReq.
+// Части не накапливаются в памяти. Возвращается результат выгрузки без частей и ошибка.
+//
+// Параметры:
+//
+// ctx - контекст выгрузки.
+// date - дата в формате YYYY-MM-DD.
+// fn - приём очередной части. Ошибка fn останавливает выгрузку и возвращается вызывающему.
+func (c *Client) FetchDayFunc(ctx context.Context, date string, fn func(PartDataDB) error) (DownloadResult, error) {
+
+	cntStr, err := c.CountRowsCtx(ctx, date)
+	if err != nil {
+		return DownloadResult{}, err
+	}
+
+	result := DownloadResult{
+		Date:   date,
+		CntStr: cntStr,
+	}
+
+	result.PageSizes, err = c.fetchPages(ctx, date, cntStr, fn)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Поток строк архивных данных за день. Строки передаются по мере приёма частей.
+// При ошибке поток возвращает её последним элементом и завершается.
+//
+// Параметры:
+//
+// ctx - контекст выгрузки.
+// date - дата в формате YYYY-MM-DD.
+func (c *Client) StreamDay(ctx context.Context, date string) iter.Seq2[DataEl, error] {
+	return c.StreamRange(ctx, date, date)
+}
+
+// Поток строк архивных данных за диапазон дат включительно. Дни выгружаются последовательно.
+// При ошибке поток возвращает её последним элементом и завершается.
+//
+// Параметры:
+//
+// ctx - контекст выгрузки.
+// from - начальная дата диапазона.
+// to - конечная дата диапазона.
+func (c *Client) StreamRange(ctx context.Context, from, to string) iter.Seq2[DataEl, error] {
+	return c.StreamRangeCount(ctx, from, to, nil)
+}
+
+// Поток строк архивных данных за диапазон дат с подсчётом строк по ответам /cntstr.
+// Количество строк всех запрошенных дней накапливается в cntStr по мере выгрузки.
+// При ошибке поток возвращает её последним элементом и завершается.
+//
+// Параметры:
+//
+// ctx - контекст выгрузки.
+// from - начальная дата диапазона.
+// to - конечная дата диапазона.
+// cntStr - получатель количества строк по ответам /cntstr (nil - без подсчёта).
+func (c *Client) StreamRangeCount(ctx context.Context, from, to string, cntStr *int) iter.Seq2[DataEl, error] {
+	return func(yield func(DataEl, error) bool) {
+
+		dates, err := DateRange(from, to)
+		if err != nil {
+			yield(DataEl{}, err)
+			return
+		}
+
+		// Без получателя количество строк не сохраняется
+		var total int
+		count := cntStr
+		if count == nil {
+			count = &total
+		}
+
+		*count = 0
+		for _, date := range dates {
+			var result DownloadResult
+			result, err = c.FetchDayFunc(ctx, date, func(part PartDataDB) error {
+				for _, row := range part.Data {
+					if !yield(row, nil) {
+						return errStopStream
+					}
+				}
+				return nil
+			})
+			*count += result.CntStr
+			if errors.Is(err, errStopStream) {
+				return
+			}
+			if err != nil {
+				yield(DataEl{}, err)
+				return
+			}
+		}
+	}
+}
+
+// Поток строк из уже принятых данных.
+func (d RxDataDB) Rows() iter.Seq2[DataEl, error] {
+	return func(yield func(DataEl, error) bool) {
+		for _, row := range d.Data {
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}
+}
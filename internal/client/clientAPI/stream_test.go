@@ -0,0 +1,134 @@
+package clientapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Поток строк за диапазон дат - порядок и содержимое
+func Test_Client_StreamRange(t *testing.T) {
+
+	days := map[string][]DataEl{
+		"2025-05-18": simRows(250),
+		"2025-05-19": simRows(0),
+		"2025-05-20": simRows(1001),
+	}
+	fs := newFakeServer(t, days)
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()), WithWorkers(4))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	got := make([]DataEl, 0)
+	for row, err := range client.StreamRange(context.Background(), "2025-05-18", "2025-05-20") {
+		require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+		got = append(got, row)
+	}
+
+	want := append(append([]DataEl{}, days["2025-05-18"]...), days["2025-05-20"]...)
+	assert.Equal(t, want, got)
+
+	// Подсчёт строк по ответам /cntstr
+	cntStr, n := -1, 0
+	for _, err := range client.StreamRangeCount(context.Background(), "2025-05-18", "2025-05-20", &cntStr) {
+		require.NoError(t, err)
+		n++
+	}
+	assert.Equal(t, 1251, n)
+	assert.Equal(t, 1251, cntStr)
+
+	// Без получателя количества строк
+	n = 0
+	for _, err := range client.StreamRangeCount(context.Background(), "2025-05-18", "2025-05-20", nil) {
+		require.NoError(t, err)
+		n++
+	}
+	assert.Equal(t, 1251, n)
+}
+
+// Поток строк - остановка получателем прекращает запросы
+func Test_Client_StreamDay_Break(t *testing.T) {
+
+	date := "2025-05-18"
+	fs := newFakeServer(t, map[string][]DataEl{date: simRows(5000)})
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	n := 0
+	for _, err := range client.StreamDay(context.Background(), date) {
+		require.NoError(t, err)
+		n++
+		if n == 150 {
+			break
+		}
+	}
+	assert.Equal(t, 150, n)
+	assert.Equal(t, 2, fs.count(pathPartDataDB), "после остановки части не запрашиваются")
+}
+
+// Поток строк - ошибка сервера передаётся последним элементом
+func Test_Client_StreamDay_Error(t *testing.T) {
+
+	date := "2025-05-18"
+	fs := newFakeServer(t, map[string][]DataEl{date: simRows(500)})
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()), WithRetry(fastRetry(1)))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	fs.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == pathPartDataDB && r.URL.Query().Get("strOffSet") == "200" {
+			http.Error(w, "database is locked", http.StatusInternalServerError)
+			return true
+		}
+		return false
+	})
+
+	rows, errs := 0, 0
+	var last error
+	for _, err := range client.StreamDay(context.Background(), date) {
+		if err != nil {
+			errs++
+			last = err
+			continue
+		}
+		rows++
+	}
+	assert.Equal(t, 200, rows, "строки до ошибки передаются")
+	assert.Equal(t, 1, errs)
+	assert.ErrorIs(t, last, ErrServer)
+}
+
+// Выгрузка дня с приёмом частей - ошибка приёма останавливает выгрузку
+func Test_Client_FetchDayFunc(t *testing.T) {
+
+	date := "2025-05-18"
+	rows := simRows(1000)
+	fs := newFakeServer(t, map[string][]DataEl{date: rows})
+
+	client, err := NewClient(fs.URL, WithHTTPClient(fs.Client()))
+	require.NoError(t, err)
+	require.NoError(t, client.Login(fs.name, fs.password))
+
+	got := make([]DataEl, 0)
+	result, err := client.FetchDayFunc(context.Background(), date, func(part PartDataDB) error {
+		got = append(got, part.Data...)
+		return nil
+	})
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, rows, got)
+	assert.Equal(t, 1000, result.CntStr)
+	assert.Len(t, result.PageSizes, 10)
+	assert.Nil(t, result.Pages, "части не накапливаются")
+
+	_, err = client.FetchDayFunc(context.Background(), date, func(part PartDataDB) error {
+		return errStopStream
+	})
+	assert.ErrorIs(t, err, errStopStream)
+}
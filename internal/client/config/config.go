@@ -0,0 +1,191 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Файл настроек клиента с именованными профилями серверов BlackBox
+	Config struct {
+		Default  string             `yaml:"default"`  // профиль по умолчанию
+		Profiles map[string]Profile `yaml:"profiles"` // профили серверов по имени
+
+		path string // путь к файлу настроек
+	}
+
+	// Профиль сервера BlackBox
+	Profile struct {
+		Name        string      `yaml:"-"`           // имя профиля
+		Host        string      `yaml:"host"`        // IP или имя HTTPS сервера
+		Port        string      `yaml:"port"`        // порт HTTPS сервера
+		CA          string      `yaml:"ca"`          // публичный ключ HTTPS сервера
+		Credentials Credentials `yaml:"credentials"` // источник имени и пароля пользователя
+		PageSize    int         `yaml:"page_size"`   // количество строк в одном запросе (0 - по умолчанию)
+		Workers     int         `yaml:"workers"`     // количество параллельных запросов (0 - по умолчанию)
+		Output      Output      `yaml:"output"`      // параметры файлов экспорта
+	}
+
+	// Источник имени и пароля пользователя
+	Credentials struct {
+		User         string `yaml:"user"`          // имя пользователя
+		PasswordEnv  string `yaml:"password_env"`  // имя переменной окружения с паролем
+		PasswordFile string `yaml:"password_file"` // файл с паролем
+	}
+
+	// Параметры файлов экспорта
+	Output struct {
+		Dir    string `yaml:"dir"`    // директория файлов экспорта
+		Name   string `yaml:"name"`   // шаблон имени файла
+		Format string `yaml:"format"` // формат файла по умолчанию
+	}
+)
+
+// Чтение файла настроек. Относительные пути профилей отсчитываются от директории файла.
+// Возвращается указатель на настройки и ошибка.
+//
+// Параметры:
+//
+// path - путь к файлу настроек в формате YAML
+func Load(path string) (*Config, error) {
+
+	if path == "" {
+		return nil, errors.New("config -> пустое значение пути к файлу настроек")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config -> ошибка чтения файла настроек: {%w}", err)
+	}
+
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err = dec.Decode(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("config -> ошибка разбора файла настроек {%s}: {%v}", path, err)
+	}
+	cfg.path = path
+
+	dir := filepath.Dir(path)
+	for name, p := range cfg.Profiles {
+		p.Name = name
+		p.CA = resolve(dir, p.CA)
+		p.Credentials.PasswordFile = resolve(dir, p.Credentials.PasswordFile)
+		p.Output.Dir = resolve(dir, p.Output.Dir)
+		cfg.Profiles[name] = p
+	}
+
+	err = cfg.validate()
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Проверка настроек. Возвращается ошибка первого некорректного значения.
+func (c *Config) validate() error {
+
+	if len(c.Profiles) == 0 {
+		return fmt.Errorf("config -> в файле настроек {%s} нет профилей", c.path)
+	}
+	if c.Default != "" {
+		if _, ok := c.Profiles[c.Default]; !ok {
+			return fmt.Errorf("config -> профиль по умолчанию {%s} не описан", c.Default)
+		}
+	}
+
+	for _, name := range c.Names() {
+		p := c.Profiles[name]
+
+		if strings.TrimSpace(name) == "" {
+			return errors.New("config -> пустое имя профиля")
+		}
+		if p.Host == "" {
+			return fmt.Errorf("config -> профиль {%s}: не задан host", name)
+		}
+		port, err := strconv.Atoi(p.Port)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("config -> профиль {%s}: недопустимое значение port: {%s}", name, p.Port)
+		}
+		if p.PageSize < 0 {
+			return fmt.Errorf("config -> профиль {%s}: отрицательное значение page_size", name)
+		}
+		if p.Workers < 0 {
+			return fmt.Errorf("config -> профиль {%s}: отрицательное значение workers", name)
+		}
+		if p.Credentials.PasswordEnv != "" && p.Credentials.PasswordFile != "" {
+			return fmt.Errorf("config -> профиль {%s}: задан и password_env, и password_file", name)
+		}
+	}
+	return nil
+}
+
+// Имена профилей в алфавитном порядке.
+func (c *Config) Names() []string {
+
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Профиль по имени. Пустое имя - профиль по умолчанию. Возвращается профиль и ошибка.
+//
+// Параметры:
+//
+// name - имя профиля
+func (c *Config) Profile(name string) (Profile, error) {
+
+	if name == "" {
+		name = c.Default
+	}
+	if name == "" {
+		return Profile{}, fmt.Errorf("config -> не выбран профиль и не задан профиль по умолчанию, доступны: {%s}", strings.Join(c.Names(), ", "))
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("config -> неизвестный профиль: {%s}, доступны: {%s}", name, strings.Join(c.Names(), ", "))
+	}
+	return p, nil
+}
+
+// Пароль пользователя из переменной окружения профиля. Возвращается пустая строка,
+// если переменная не задана в профиле, и ошибка, если названная в профиле переменная окружения
+// не задана или пуста: пароль другого источника не должен отправляться серверу профиля.
+func (c Credentials) Password() (string, error) {
+
+	if c.PasswordEnv == "" {
+		return "", nil
+	}
+	password := os.Getenv(c.PasswordEnv)
+	if password == "" {
+		return "", fmt.Errorf("config -> не задана переменная окружения {%s} с паролем пользователя", c.PasswordEnv)
+	}
+	return password, nil
+}
+
+// Путь относительно директории файла настроек.
+//
+// Параметры:
+//
+// dir - директория файла настроек
+// path - путь из профиля
+func resolve(dir, path string) string {
+
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
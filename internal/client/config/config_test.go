@@ -0,0 +1,149 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Запись файла настроек во временную директорию. Возвращается путь к файлу.
+func writeConfig(t *testing.T, text string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(text), 0o600))
+	return path
+}
+
+// Чтение файла настроек и выбор профиля
+func Test_Load(t *testing.T) {
+
+	path := writeConfig(t, `
+default: boiler1
+profiles:
+  boiler1:
+    host: 192.168.1.10
+    port: 8443
+    ca: certs/boiler1.crt
+    credentials:
+      user: operator
+      password_env: BOILER1_PASSWORD
+    page_size: 500
+    workers: 4
+    output:
+      dir: export/boiler1
+      name: "{server}_{range}"
+      format: csv
+  compressor:
+    host: 192.168.2.20
+    port: "9443"
+    ca: /etc/blackbox/compressor.crt
+    credentials:
+      user: operator
+      password_file: compressor.pass
+`)
+	dir := filepath.Dir(path)
+
+	cfg, err := Load(path)
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, []string{"boiler1", "compressor"}, cfg.Names())
+
+	// Профиль по умолчанию
+	p, err := cfg.Profile("")
+	require.NoError(t, err)
+	assert.Equal(t, Profile{
+		Name:        "boiler1",
+		Host:        "192.168.1.10",
+		Port:        "8443",
+		CA:          filepath.Join(dir, "certs/boiler1.crt"),
+		Credentials: Credentials{User: "operator", PasswordEnv: "BOILER1_PASSWORD"},
+		PageSize:    500,
+		Workers:     4,
+		Output:      Output{Dir: filepath.Join(dir, "export/boiler1"), Name: "{server}_{range}", Format: "csv"},
+	}, p)
+
+	t.Setenv("BOILER1_PASSWORD", "")
+	_, err = p.Credentials.Password()
+	assert.ErrorContains(t, err, "BOILER1_PASSWORD", "переменная пароля профиля пуста")
+
+	t.Setenv("BOILER1_PASSWORD", "secret")
+	password, err := p.Credentials.Password()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", password)
+
+	// Профиль по имени, абсолютные пути не меняются
+	p, err = cfg.Profile("compressor")
+	require.NoError(t, err)
+	assert.Equal(t, "9443", p.Port)
+	assert.Equal(t, "/etc/blackbox/compressor.crt", p.CA)
+	assert.Equal(t, filepath.Join(dir, "compressor.pass"), p.Credentials.PasswordFile)
+	password, err = p.Credentials.Password()
+	require.NoError(t, err)
+	assert.Empty(t, password, "пароль из файла")
+
+	_, err = cfg.Profile("pump")
+	require.Error(t, err)
+	assert.Equal(t, "config -> неизвестный профиль: {pump}, доступны: {boiler1, compressor}", err.Error())
+}
+
+// Чтение файла настроек - ошибки
+func Test_Load_Error(t *testing.T) {
+
+	argData := []struct {
+		testName string
+		text     string
+		wantErr  string
+	}{
+		{
+			testName: "нет профилей",
+			text:     "default: \"\"\n",
+			wantErr:  "нет профилей",
+		},
+		{
+			testName: "неизвестное поле",
+			text:     "profiles:\n  a:\n    host: h\n    port: 1\n    adress: x\n",
+			wantErr:  "field adress not found",
+		},
+		{
+			testName: "нет хоста",
+			text:     "profiles:\n  a:\n    port: 1\n",
+			wantErr:  "config -> профиль {a}: не задан host",
+		},
+		{
+			testName: "недопустимый порт",
+			text:     "profiles:\n  a:\n    host: h\n    port: 70000\n",
+			wantErr:  "config -> профиль {a}: недопустимое значение port: {70000}",
+		},
+		{
+			testName: "неизвестный профиль по умолчанию",
+			text:     "default: b\nprofiles:\n  a:\n    host: h\n    port: 1\n",
+			wantErr:  "config -> профиль по умолчанию {b} не описан",
+		},
+		{
+			testName: "два источника пароля",
+			text:     "profiles:\n  a:\n    host: h\n    port: 1\n    credentials:\n      password_env: P\n      password_file: p\n",
+			wantErr:  "config -> профиль {a}: задан и password_env, и password_file",
+		},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.text))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	_, err := Load(filepath.Join(t.TempDir(), "none.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// Без профиля по умолчанию имя профиля обязательно
+	cfg, err := Load(writeConfig(t, "profiles:\n  a:\n    host: h\n    port: 1\n"))
+	require.NoError(t, err)
+	_, err = cfg.Profile("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "не выбран профиль")
+}
@@ -0,0 +1,243 @@
+package fleet
+
+import (
+	clientapi "clienthttps/internal/client/clientAPI"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Итог задания по объекту
+type Status int
+
+const (
+	Succeeded   Status = iota // задание выполнено
+	Failed                    // ошибка выполнения задания
+	Unreachable               // сервер объекта недоступен
+)
+
+// Наименование итога задания.
+func (s Status) String() string {
+	switch s {
+	case Succeeded:
+		return "выполнено"
+	case Failed:
+		return "ошибка"
+	case Unreachable:
+		return "недоступен"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// Итог задания по ошибке. Сетевые ошибки означают недоступность сервера объекта.
+//
+// Параметры:
+//
+// err - ошибка задания
+func classify(err error) Status {
+
+	switch {
+	case err == nil:
+		return Succeeded
+	case errors.Is(err, clientapi.ErrNetwork):
+		return Unreachable
+	}
+	return Failed
+}
+
+type (
+	// Задание по объекту. Возвращается описание результата (например, имя созданного файла) и ошибка.
+	Job func(ctx context.Context, target string) (detail string, err error)
+
+	// Результат задания по объекту
+	Result struct {
+		Target   string        // имя объекта
+		Status   Status        // итог задания
+		Detail   string        // описание результата
+		Err      error         // ошибка задания
+		Duration time.Duration // время выполнения задания
+	}
+
+	// Выполнение заданий по списку объектов с ограничением количества одновременных заданий
+	Runner struct {
+		Workers int          // количество одновременных заданий (0 - по одному)
+		OnDone  func(Result) // вызывается по завершении задания каждого объекта
+	}
+
+	// Отчёт о выполнении заданий в порядке списка объектов
+	Report struct {
+		Results []Result
+	}
+)
+
+// Выполнение задания по каждому объекту. Ошибка задания одного объекта не прерывает остальные,
+// отмена контекста прекращает запуск новых заданий. Возвращается отчёт в порядке списка объектов.
+//
+// Параметры:
+//
+// ctx - контекст выполнения
+// targets - имена объектов
+// job - задание по объекту
+func (r Runner) Run(ctx context.Context, targets []string, job Job) Report {
+
+	rep := Report{Results: make([]Result, len(targets))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(r.Workers, 1))
+
+	for i, target := range targets {
+
+		select {
+		case <-ctx.Done():
+			// Объекты, до которых не дошла очередь
+			for j := i; j < len(targets); j++ {
+				rep.Results[j] = Result{Target: targets[j], Status: Failed, Err: ctx.Err()}
+			}
+			wg.Wait()
+			return rep
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			start := time.Now()
+			detail, err := job(ctx, target)
+			res := Result{
+				Target:   target,
+				Status:   classify(err),
+				Detail:   detail,
+				Err:      err,
+				Duration: time.Since(start),
+			}
+			rep.Results[i] = res
+
+			if r.OnDone != nil {
+				mu.Lock()
+				r.OnDone(res)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	return rep
+}
+
+// Количество объектов с заданным итогом.
+//
+// Параметры:
+//
+// s - итог задания
+func (rep Report) Count(s Status) int {
+
+	n := 0
+	for _, res := range rep.Results {
+		if res.Status == s {
+			n++
+		}
+	}
+	return n
+}
+
+// Признак выполнения заданий по всем объектам.
+func (rep Report) OK() bool {
+	return rep.Count(Succeeded) == len(rep.Results)
+}
+
+// Вывод отчёта таблицей с итоговой строкой. Возвращается ошибка.
+//
+// Параметры:
+//
+// w - приёмник отчёта
+func (rep Report) WriteText(w io.Writer) error {
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Объект\tИтог\tВремя\tРезультат")
+	for _, res := range rep.Results {
+		detail := res.Detail
+		if res.Err != nil {
+			detail = res.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", res.Target, res.Status, res.Duration.Round(time.Millisecond), detail)
+	}
+	err := tw.Flush()
+	if err != nil {
+		return fmt.Errorf("fleet -> ошибка вывода отчёта: {%v}", err)
+	}
+
+	_, err = fmt.Fprintf(w, "Выполнено {%d}, ошибка {%d}, недоступно {%d}, всего {%d}\n",
+		rep.Count(Succeeded), rep.Count(Failed), rep.Count(Unreachable), len(rep.Results))
+	if err != nil {
+		return fmt.Errorf("fleet -> ошибка вывода отчёта: {%v}", err)
+	}
+	return nil
+}
+
+// Запись отчёта в формате JSON. Возвращается ошибка.
+//
+// Параметры:
+//
+// w - приёмник отчёта
+func (rep Report) WriteJSON(w io.Writer) error {
+
+	type jsonResult struct {
+		Target   string  `json:"target"`
+		Status   string  `json:"status"`
+		Detail   string  `json:"detail,omitempty"`
+		Error    string  `json:"error,omitempty"`
+		Duration float64 `json:"duration_sec"`
+	}
+	type jsonReport struct {
+		Succeeded   int          `json:"succeeded"`
+		Failed      int          `json:"failed"`
+		Unreachable int          `json:"unreachable"`
+		Results     []jsonResult `json:"results"`
+	}
+
+	out := jsonReport{
+		Succeeded:   rep.Count(Succeeded),
+		Failed:      rep.Count(Failed),
+		Unreachable: rep.Count(Unreachable),
+		Results:     make([]jsonResult, 0, len(rep.Results)),
+	}
+	for _, res := range rep.Results {
+		jr := jsonResult{
+			Target:   res.Target,
+			Status:   statusKey(res.Status),
+			Detail:   res.Detail,
+			Duration: res.Duration.Seconds(),
+		}
+		if res.Err != nil {
+			jr.Error = res.Err.Error()
+		}
+		out.Results = append(out.Results, jr)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(out)
+	if err != nil {
+		return fmt.Errorf("fleet -> ошибка записи отчёта: {%v}", err)
+	}
+	return nil
+}
+
+// Ключ итога задания в отчёте JSON.
+func statusKey(s Status) string {
+	switch s {
+	case Succeeded:
+		return "succeeded"
+	case Unreachable:
+		return "unreachable"
+	}
+	return "failed"
+}
@@ -0,0 +1,131 @@
+package fleet
+
+import (
+	"bytes"
+	clientapi "clienthttps/internal/client/clientAPI"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Выполнение заданий - ограничение одновременных заданий и итоги по объектам
+func Test_Runner_Run(t *testing.T) {
+
+	targets := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		targets = append(targets, fmt.Sprintf("obj%02d", i))
+	}
+
+	var running, peak atomic.Int32
+	job := func(ctx context.Context, target string) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+
+		switch target {
+		case "obj03":
+			return "", fmt.Errorf("login -> %w", clientapi.ErrNetwork)
+		case "obj07":
+			return "", errors.New("ошибка сохранения")
+		}
+		return target + ".xlsx", nil
+	}
+
+	var done atomic.Int32
+	rep := Runner{Workers: 4, OnDone: func(Result) { done.Add(1) }}.Run(context.Background(), targets, job)
+
+	assert.LessOrEqual(t, peak.Load(), int32(4), "одновременных заданий не больше Workers")
+	assert.Equal(t, int32(20), done.Load())
+	require.Len(t, rep.Results, 20)
+
+	for i, res := range rep.Results {
+		assert.Equal(t, targets[i], res.Target, "результаты в порядке списка объектов")
+	}
+	assert.Equal(t, Unreachable, rep.Results[3].Status)
+	assert.Equal(t, Failed, rep.Results[7].Status)
+	assert.Equal(t, Succeeded, rep.Results[0].Status)
+	assert.Equal(t, "obj00.xlsx", rep.Results[0].Detail)
+
+	assert.Equal(t, 18, rep.Count(Succeeded))
+	assert.Equal(t, 1, rep.Count(Failed))
+	assert.Equal(t, 1, rep.Count(Unreachable))
+	assert.False(t, rep.OK())
+}
+
+// Выполнение заданий - отмена контекста прекращает запуск новых заданий
+func Test_Runner_Canceled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	job := func(ctx context.Context, target string) (string, error) {
+		if calls.Add(1) == 2 {
+			cancel()
+		}
+		return "", ctx.Err()
+	}
+
+	rep := Runner{Workers: 1}.Run(ctx, []string{"a", "b", "c", "d"}, job)
+	require.Len(t, rep.Results, 4)
+	assert.LessOrEqual(t, int(calls.Load()), 3)
+	assert.Equal(t, "d", rep.Results[3].Target)
+	assert.Equal(t, Failed, rep.Results[3].Status)
+	assert.ErrorIs(t, rep.Results[3].Err, context.Canceled)
+}
+
+// Вывод отчёта
+func Test_Report_Write(t *testing.T) {
+
+	rep := Report{Results: []Result{
+		{Target: "boiler1", Status: Succeeded, Detail: "export/boiler1/a.xlsx", Duration: 1500 * time.Millisecond},
+		{Target: "compressor", Status: Unreachable, Err: errors.New("сервер недоступен"), Duration: time.Second},
+		{Target: "pump", Status: Failed, Err: errors.New("неверный пароль")},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteText(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, []string{"Объект", "Итог", "Время", "Результат"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"boiler1", "выполнено", "1.5s", "export/boiler1/a.xlsx"}, strings.Fields(lines[1]))
+	assert.Contains(t, lines[2], "недоступен")
+	assert.Contains(t, lines[2], "сервер недоступен")
+	assert.Equal(t, "Выполнено {1}, ошибка {1}, недоступно {1}, всего {3}", lines[4])
+
+	buf.Reset()
+	require.NoError(t, rep.WriteJSON(&buf))
+	var got struct {
+		Succeeded   int `json:"succeeded"`
+		Failed      int `json:"failed"`
+		Unreachable int `json:"unreachable"`
+		Results     []struct {
+			Target string `json:"target"`
+			Status string `json:"status"`
+			Detail string `json:"detail"`
+			Error  string `json:"error"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, 1, got.Succeeded)
+	assert.Equal(t, 1, got.Failed)
+	assert.Equal(t, 1, got.Unreachable)
+	require.Len(t, got.Results, 3)
+	assert.Equal(t, "unreachable", got.Results[1].Status)
+	assert.Equal(t, "сервер недоступен", got.Results[1].Error)
+	assert.Equal(t, "export/boiler1/a.xlsx", got.Results[0].Detail)
+}
@@ -0,0 +1,129 @@
+package libre
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Числовое значение. Отступы значения не учитываются, NaN и бесконечность числами не считаются.
+// Возвращается число и признак успешного разбора.
+//
+// Параметры:
+//
+// v - значение из архивных данных
+func parseNumber(v string) (float64, bool) {
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// Метка времени архивных данных в формате RFC3339 с долями секунды.
+// Возвращается время и признак успешного разбора.
+//
+// Параметры:
+//
+// v - метка времени из архивных данных
+func parseTimeStamp(v string) (time.Time, bool) {
+
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(v))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// Числовой формат ячеек времени по умолчанию
+const DefaultTimeFormat = "yyyy-mm-dd hh:mm:ss.000"
+
+// Параметры записи значений в ячейки книги.
+// Значения разбираются в числа, логические значения и даты. Значения, которые не разбираются, остаются текстом.
+type CellFormat struct {
+	Text       bool           // все значения записываются текстом, как приняты от сервера
+	Location   *time.Location // часовой пояс отображения меток времени (nil - пояс метки)
+	TimeFormat string         // числовой формат ячеек времени (пусто - DefaultTimeFormat)
+}
+
+// Числовой формат ячеек времени.
+func (cf CellFormat) timeFormat() string {
+	if cf.TimeFormat == "" {
+		return DefaultTimeFormat
+	}
+	return cf.TimeFormat
+}
+
+// Значение ячейки столбца Value: логическое значение, число или текст.
+// Значения 0 и 1 дискретных переменных (Coil, DI) записываются логическими значениями.
+//
+// Параметры:
+//
+// name - имя переменной
+// v - значение из архивных данных
+func (cf CellFormat) value(name, v string) any {
+
+	if cf.Text {
+		return v
+	}
+
+	t := strings.TrimSpace(v)
+	switch strings.ToLower(t) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if isBoolTag(name) && (t == "0" || t == "1") {
+		return t == "1"
+	}
+
+	if f, ok := parseNumber(t); ok {
+		return f
+	}
+	return v
+}
+
+// Значение ячейки столбца Quality: число или текст.
+func (cf CellFormat) quality(v string) any {
+
+	if cf.Text {
+		return v
+	}
+	if f, ok := parseNumber(v); ok {
+		return f
+	}
+	return v
+}
+
+// Значение ячейки столбца TimeStamp: время в часовом поясе отображения или текст.
+func (cf CellFormat) timeStamp(v string) any {
+
+	if cf.Text {
+		return v
+	}
+
+	t, ok := parseTimeStamp(v)
+	if !ok {
+		return v
+	}
+	if cf.Location != nil {
+		t = t.In(cf.Location)
+	}
+	return t
+}
+
+// Признак дискретной переменной по имени: часть имени между точками Coil или DI.
+// Пример: "Dev3. Coil. Тестовая переменная Bool".
+func isBoolTag(name string) bool {
+
+	for _, part := range strings.Split(name, ".") {
+		switch strings.TrimSpace(part) {
+		case "Coil", "DI":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package libre
+
+import (
+	"bytes"
+	clientapi "clienthttps/internal/client/clientAPI"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// Значения ячеек по типам
+func Test_CellFormat_value(t *testing.T) {
+
+	argData := []struct {
+		testName string
+		name     string
+		value    string
+		want     any
+	}{
+		{testName: "целое с отступом", name: "Dev3. HR. Тестовая переменная Word", value: "    1672", want: 1672.0},
+		{testName: "дробное", name: "Dev1. Тестовая переменная Real", value: "-12.5", want: -12.5},
+		{testName: "дискретная 1", name: "Dev2. Coil. Тестовая переменная Bool", value: "    1", want: true},
+		{testName: "дискретная 0", name: "Dev2. DI. Вход", value: "0", want: false},
+		{testName: "не дискретная 1", name: "Dev2. HR. Coil счётчик", value: "1", want: 1.0},
+		{testName: "логическое значение", name: "Dev1. Флаг", value: "TRUE", want: true},
+		{testName: "текст", name: "Dev1. Состояние", value: "нет связи", want: "нет связи"},
+		{testName: "NaN", name: "Dev1. Тестовая переменная Real", value: "NaN", want: "NaN"},
+		{testName: "бесконечность", name: "Dev1. Тестовая переменная Real", value: "-Infinity", want: "-Infinity"},
+		{testName: "Inf", name: "Dev1. Тестовая переменная Real", value: "+Inf", want: "+Inf"},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+			assert.Equal(t, tt.want, CellFormat{}.value(tt.name, tt.value))
+			assert.Equal(t, tt.value, CellFormat{Text: true}.value(tt.name, tt.value), "текстовый режим")
+		})
+	}
+
+	assert.Equal(t, 1.0, CellFormat{}.quality("1"))
+	assert.Equal(t, "?", CellFormat{}.quality("?"))
+}
+
+// Метки времени в часовом поясе отображения
+func Test_CellFormat_timeStamp(t *testing.T) {
+
+	ts := "2025-05-18T03:01:02.84024+07:00"
+
+	got, ok := CellFormat{}.timeStamp(ts).(time.Time)
+	require.True(t, ok)
+	assert.Equal(t, "2025-05-18 03:01:02.84024 +0700", got.Format("2006-01-02 15:04:05.99999 -0700"), "по умолчанию - пояс метки")
+
+	got, ok = CellFormat{Location: time.UTC}.timeStamp(ts).(time.Time)
+	require.True(t, ok)
+	assert.Equal(t, "2025-05-17 20:01:02.84024", got.Format("2006-01-02 15:04:05.99999"))
+
+	assert.Equal(t, "18.05.2025 03:01", CellFormat{}.timeStamp("18.05.2025 03:01"), "метка не в формате RFC3339 остаётся текстом")
+	assert.Equal(t, ts, CellFormat{Text: true}.timeStamp(ts))
+}
+
+// Экспорт xlsx - типы ячеек, часовой пояс и формат времени
+func Test_XLSX_Export_Typed(t *testing.T) {
+
+	data := clientapi.RxDataDB{
+		StartDate: "2025-05-18",
+		Data: []clientapi.DataEl{
+			{Name: "Dev3. HR. Тестовая переменная Word", Value: "    1672", Qual: "1", TimeStamp: "2025-05-18T03:01:02.5+07:00"},
+			{Name: "Dev3. Coil. Тестовая переменная Bool", Value: "1", Qual: "0", TimeStamp: "нет времени"},
+			{Name: "Dev1. Тестовая переменная Real", Value: "NaN", Qual: "0", TimeStamp: "2025-05-18T03:01:03+07:00"},
+		},
+	}
+
+	exp := XLSX{Cells: CellFormat{Location: time.UTC, TimeFormat: "dd.mm.yyyy hh:mm:ss"}}
+
+	var buf bytes.Buffer
+	_, err := exp.Export(&buf, Dataset{StartDate: data.StartDate, Rows: data.Rows()})
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+
+	file, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+	defer file.Close()
+
+	argData := []struct {
+		cell     string
+		wantType excelize.CellType
+		want     string
+	}{
+		{cell: "B2", wantType: excelize.CellTypeUnset, want: "1672"},
+		{cell: "C2", wantType: excelize.CellTypeUnset, want: "1"},
+		{cell: "D2", wantType: excelize.CellTypeUnset, want: "17.05.2025 20:01:02"},
+		{cell: "B3", wantType: excelize.CellTypeBool, want: "TRUE"},
+		{cell: "D3", wantType: excelize.CellTypeInlineString, want: "нет времени"},
+		{cell: "B4", wantType: excelize.CellTypeInlineString, want: "NaN"},
+	}
+
+	for _, tt := range argData {
+		typ, err := file.GetCellType("DataDB", tt.cell)
+		require.NoError(t, err)
+		assert.Equalf(t, tt.wantType, typ, "тип ячейки {%s}", tt.cell)
+
+		v, err := file.GetCellValue("DataDB", tt.cell)
+		require.NoError(t, err)
+		assert.Equalf(t, tt.want, v, "значение ячейки {%s}", tt.cell)
+	}
+}
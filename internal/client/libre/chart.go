@@ -0,0 +1,233 @@
+package libre
+
+import (
+	clientapi "clienthttps/internal/client/clientAPI"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Графики значений переменных на листе Charts
+type ChartMode int
+
+const (
+	ChartNone   ChartMode = iota // без графиков
+	ChartTag                     // график на переменную
+	ChartDevice                  // график на устройство со всеми его переменными
+)
+
+// Имена листов графиков и данных графиков
+const (
+	chartsSheet    = "Charts"
+	chartDataSheet = "ChartData"
+)
+
+// Формат меток времени на оси графиков
+const chartTimeFormat = "yyyy-mm-dd hh:mm"
+
+// Точка графика
+type chartPoint struct {
+	t time.Time
+	v float64
+}
+
+// Ряд значений одной переменной
+type chartSeries struct {
+	name   string
+	group  string
+	step   bool // дискретная переменная, рисуется ступенчатым графиком
+	points []chartPoint
+}
+
+// Сборка графиков. Числовые и логические значения с разбираемой меткой времени накапливаются в памяти.
+type chartBuilder struct {
+	mode   ChartMode
+	series []*chartSeries          // ряды по порядку первого появления
+	index  map[string]*chartSeries // ряд по имени переменной
+}
+
+// Создание сборки графиков.
+func newChartBuilder(mode ChartMode) *chartBuilder {
+	return &chartBuilder{mode: mode, index: make(map[string]*chartSeries)}
+}
+
+// Добавление строки архивных данных. Текстовые значения и неразбираемые метки времени пропускаются.
+func (c *chartBuilder) add(row clientapi.DataEl) {
+
+	t, ok := parseTimeStamp(row.TimeStamp)
+	if !ok {
+		return
+	}
+	v, ok := numericValue(row.Name, row.Value)
+	if !ok {
+		return
+	}
+
+	s, ok := c.index[row.Name]
+	if !ok {
+		s = &chartSeries{name: row.Name, group: row.Name}
+		if c.mode == ChartDevice {
+			s.group = SplitDevice.group(row.Name)
+		}
+		c.index[row.Name] = s
+		c.series = append(c.series, s)
+	}
+
+	if _, ok := (CellFormat{}).value(row.Name, row.Value).(bool); ok {
+		s.step = true
+	}
+	s.points = append(s.points, chartPoint{t: t, v: v})
+}
+
+// Точки ряда по возрастанию времени, не более limit точек. Для ступенчатого графика перед каждым изменением
+// добавляется точка с прежним значением.
+//
+// Параметры:
+//
+// limit - наибольшее количество точек ряда
+func (s *chartSeries) sorted(limit int) []chartPoint {
+
+	slices.SortStableFunc(s.points, func(a, b chartPoint) int {
+		return a.t.Compare(b.t)
+	})
+	if !s.step {
+		return decimate(s.points, limit)
+	}
+
+	// Ступенька удваивает точки изменений значения
+	points := decimate(s.points, (limit+1)/2)
+	out := make([]chartPoint, 0, 2*len(points))
+	for i, p := range points {
+		if i > 0 && p.v != points[i-1].v {
+			out = append(out, chartPoint{t: p.t, v: points[i-1].v})
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// Прореживание точек до limit точек, равномерно распределённых по ряду. Первая и последняя точки сохраняются.
+//
+// Параметры:
+//
+// points - точки по возрастанию времени
+// limit - наибольшее количество точек
+func decimate(points []chartPoint, limit int) []chartPoint {
+
+	if len(points) <= limit {
+		return points
+	}
+	if limit < 2 {
+		return points[:limit]
+	}
+
+	out := make([]chartPoint, limit)
+	for i := range out {
+		out[i] = points[i*(len(points)-1)/(limit-1)]
+	}
+	return out
+}
+
+// Запись скрытого листа данных графиков ChartData и листа графиков Charts.
+// Каждой переменной на листе данных отводятся два столбца: метка времени и значение.
+// Ряды, точки которых не вмещает лист, прореживаются.
+//
+// Параметры:
+//
+// file - книга
+// cf - параметры записи значений в ячейки
+// timeStyle - стиль ячеек времени
+func (c *chartBuilder) write(file *excelize.File, cf CellFormat, timeStyle int) error {
+
+	if len(c.series) == 0 {
+		return nil
+	}
+	if 2*len(c.series) > excelize.MaxColumns {
+		return fmt.Errorf("xlsx -> переменных {%d} больше, чем вмещает лист {%s}", len(c.series), chartDataSheet)
+	}
+
+	// Лист данных графиков
+	points := make([][]chartPoint, len(c.series))
+	header := make([]any, 0, 2*len(c.series))
+	length := 0
+	for i, s := range c.series {
+		points[i] = s.sorted(sheetRows - 1)
+		length = max(length, len(points[i]))
+		header = append(header, "TimeStamp:", s.name)
+	}
+
+	data, err := newSheetWriter(file, chartDataSheet, header, false)
+	if err != nil {
+		return err
+	}
+	for row := 0; row < length; row++ {
+		values := make([]any, 2*len(points))
+		for i, p := range points {
+			if row >= len(p) {
+				continue
+			}
+			t := p[row].t
+			if cf.Location != nil {
+				t = t.In(cf.Location)
+			}
+			values[2*i] = excelize.Cell{StyleID: timeStyle, Value: t}
+			values[2*i+1] = p[row].v
+		}
+		err = data.add(values)
+		if err != nil {
+			return err
+		}
+	}
+	err = data.flush()
+	if err != nil {
+		return err
+	}
+
+	// Лист графиков: график на группу рядов
+	_, err = file.NewSheet(chartsSheet)
+	if err != nil {
+		return fmt.Errorf("xlsx -> ошибка при добавлении вкладки {%s}: {%v}", chartsSheet, err)
+	}
+
+	groups := make([]string, 0)
+	series := make(map[string][]excelize.ChartSeries)
+	for i, s := range c.series {
+		timeCol, _ := excelize.ColumnNumberToName(2*i + 1)
+		valueCol, _ := excelize.ColumnNumberToName(2*i + 2)
+		last := len(points[i]) + 1
+
+		if _, ok := series[s.group]; !ok {
+			groups = append(groups, s.group)
+		}
+		series[s.group] = append(series[s.group], excelize.ChartSeries{
+			Name:       fmt.Sprintf("'%s'!$%s$1", chartDataSheet, valueCol),
+			Categories: fmt.Sprintf("'%s'!$%s$2:$%s$%d", chartDataSheet, timeCol, timeCol, last),
+			Values:     fmt.Sprintf("'%s'!$%s$2:$%s$%d", chartDataSheet, valueCol, valueCol, last),
+			Marker:     excelize.ChartMarker{Symbol: "none"},
+			Line:       excelize.ChartLine{Width: 1.5},
+		})
+	}
+
+	for i, group := range groups {
+		err = file.AddChart(chartsSheet, fmt.Sprintf("A%d", 1+20*i), &excelize.Chart{
+			Type:      excelize.Scatter,
+			Series:    series[group],
+			Title:     []excelize.RichTextRun{{Text: group}},
+			Dimension: excelize.ChartDimension{Width: 960, Height: 360},
+			Legend:    excelize.ChartLegend{Position: "bottom"},
+			XAxis:     excelize.ChartAxis{NumFmt: excelize.ChartNumFmt{CustomNumFmt: chartTimeFormat}, MajorGridLines: true},
+			YAxis:     excelize.ChartAxis{MajorGridLines: true},
+		})
+		if err != nil {
+			return fmt.Errorf("xlsx -> ошибка добавления графика {%s}: {%v}", group, err)
+		}
+	}
+
+	err = file.SetSheetVisible(chartDataSheet, false)
+	if err != nil {
+		return fmt.Errorf("xlsx -> ошибка скрытия вкладки {%s}: {%v}", chartDataSheet, err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package libre
+
+import (
+	"archive/zip"
+	"bytes"
+	clientapi "clienthttps/internal/client/clientAPI"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// Точки ступенчатого графика дискретной переменной
+func Test_chartSeries_sorted(t *testing.T) {
+
+	c := newChartBuilder(ChartTag)
+	for _, row := range []clientapi.DataEl{
+		{Name: "Dev2. Coil. Bool", Value: "1", TimeStamp: "2025-05-18T03:01:07+07:00"},
+		{Name: "Dev2. Coil. Bool", Value: "0", TimeStamp: "2025-05-18T03:01:02+07:00"},
+		{Name: "Dev2. Coil. Bool", Value: "1", TimeStamp: "2025-05-18T03:01:12+07:00"},
+		{Name: "Dev3. HR. Word", Value: "5", TimeStamp: "2025-05-18T03:01:12+07:00"},
+		{Name: "Dev3. HR. Word", Value: "4", TimeStamp: "2025-05-18T03:01:02+07:00"},
+		{Name: "Dev3. HR. Word", Value: "нет связи", TimeStamp: "2025-05-18T03:01:07+07:00"},
+	} {
+		c.add(row)
+	}
+	require.Len(t, c.series, 2)
+
+	got := func(points []chartPoint) []string {
+		out := make([]string, 0, len(points))
+		for _, p := range points {
+			out = append(out, p.t.Format("05")+"="+strconv.FormatFloat(p.v, 'g', -1, 64))
+		}
+		return out
+	}
+
+	assert.True(t, c.series[0].step)
+	assert.Equal(t, []string{"02=0", "07=0", "07=1", "12=1"}, got(c.series[0].sorted(sheetRows)), "ступенька перед изменением значения")
+
+	assert.False(t, c.series[1].step)
+	assert.Equal(t, []string{"02=4", "12=5"}, got(c.series[1].sorted(sheetRows)), "текстовые значения пропускаются")
+
+	assert.Equal(t, []string{"02=0", "12=0", "12=1"}, got(c.series[0].sorted(3)), "прореживание перед ступенькой")
+	assert.Equal(t, []string{"02=4"}, got(c.series[1].sorted(1)))
+}
+
+// Прореживание точек ряда
+func Test_decimate(t *testing.T) {
+
+	points := make([]chartPoint, 10)
+	for i := range points {
+		points[i] = chartPoint{v: float64(i)}
+	}
+	values := func(points []chartPoint) []float64 {
+		out := make([]float64, 0, len(points))
+		for _, p := range points {
+			out = append(out, p.v)
+		}
+		return out
+	}
+
+	argData := []struct {
+		testName string
+		limit    int
+		want     []float64
+	}{
+		{testName: "без прореживания", limit: 10, want: []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{testName: "первая и последняя точки", limit: 4, want: []float64{0, 3, 6, 9}},
+		{testName: "две точки", limit: 2, want: []float64{0, 9}},
+		{testName: "одна точка", limit: 1, want: []float64{0}},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+			assert.Equal(t, tt.want, values(decimate(points, tt.limit)))
+		})
+	}
+}
+
+// Экспорт xlsx с графиками по переменным и устройствам
+func Test_XLSX_Export_Chart(t *testing.T) {
+
+	data := clientapi.RxDataDB{StartDate: "2025-05-18"}
+	for i := 0; i < 10; i++ {
+		ts := time.Date(2025, 5, 18, 3, 1, i, 0, time.FixedZone("", 7*3600)).Format(time.RFC3339Nano)
+		data.Data = append(data.Data,
+			clientapi.DataEl{Name: "Dev3. HR. Word", Value: "1672", Qual: "1", TimeStamp: ts},
+			clientapi.DataEl{Name: "Dev3. Coil. Bool", Value: []string{"0", "1"}[i%2], Qual: "1", TimeStamp: ts},
+			clientapi.DataEl{Name: "Dev1. HR. Word", Value: "7", Qual: "1", TimeStamp: ts},
+		)
+	}
+
+	argData := []struct {
+		testName   string
+		mode       ChartMode
+		wantCharts int
+	}{
+		{testName: "по переменным", mode: ChartTag, wantCharts: 3},
+		{testName: "по устройствам", mode: ChartDevice, wantCharts: 2},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+
+			var buf bytes.Buffer
+			_, err := XLSX{Chart: tt.mode}.Export(&buf, Dataset{StartDate: data.StartDate, Rows: data.Rows()})
+			require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+
+			zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			require.NoError(t, err)
+			charts := 0
+			for _, f := range zr.File {
+				if strings.HasPrefix(f.Name, "xl/charts/chart") {
+					charts++
+				}
+			}
+			assert.Equal(t, tt.wantCharts, charts)
+
+			file, err := excelize.OpenReader(&buf)
+			require.NoError(t, err)
+			defer file.Close()
+
+			assert.Equal(t, []string{"DataDB", "ChartData", "Charts", "Summary"}, file.GetSheetList())
+			visible, err := file.GetSheetVisible(chartDataSheet)
+			require.NoError(t, err)
+			assert.False(t, visible, "лист данных графиков скрыт")
+
+			rows, err := file.GetRows(chartDataSheet)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"TimeStamp:", "Dev3. HR. Word", "TimeStamp:", "Dev3. Coil. Bool", "TimeStamp:", "Dev1. HR. Word"}, rows[0])
+			assert.Len(t, rows, 1+19, "ступенчатый график дискретной переменной - 19 точек")
+		})
+	}
+}
+
+// Экспорт xlsx с графиками, точки которых не вмещает лист данных графиков
+func Test_XLSX_Export_ChartDecimate(t *testing.T) {
+
+	limit := sheetRows
+	sheetRows = 8
+	t.Cleanup(func() { sheetRows = limit })
+
+	data := clientapi.RxDataDB{StartDate: "2025-05-18"}
+	for i := 0; i < 20; i++ {
+		ts := time.Date(2025, 5, 18, 3, 1, i, 0, time.FixedZone("", 7*3600)).Format(time.RFC3339Nano)
+		data.Data = append(data.Data,
+			clientapi.DataEl{Name: "Dev3. HR. Word", Value: strconv.Itoa(i), Qual: "1", TimeStamp: ts},
+			clientapi.DataEl{Name: "Dev3. Coil. Bool", Value: []string{"0", "1"}[i%2], Qual: "1", TimeStamp: ts},
+		)
+	}
+
+	var buf bytes.Buffer
+	cnt, err := XLSX{Chart: ChartTag}.Export(&buf, Dataset{StartDate: data.StartDate, Rows: data.Rows()})
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, 40, cnt, "листы данных записаны полностью")
+
+	file, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+	defer file.Close()
+
+	rows, err := file.GetRows(chartDataSheet)
+	require.NoError(t, err)
+	require.Len(t, rows, sheetRows, "точки прорежены до размера листа")
+	assert.Equal(t, "0", rows[1][1], "первая точка")
+	assert.Equal(t, "19", rows[7][1], "последняя точка")
+}
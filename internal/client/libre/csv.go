@@ -0,0 +1,121 @@
+package libre
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Правило заключения значений в кавычки
+type QuoteMode int
+
+const (
+	QuoteMinimal QuoteMode = iota // в кавычки берутся значения с разделителем, кавычкой или переводом строки
+	QuoteAll                      // в кавычки берутся все значения
+	QuoteNone                     // значения записываются без кавычек
+)
+
+// Экспорт в текстовый файл с разделителем столбцов (CSV, TSV).
+type CSV struct {
+	Delimiter  rune      // разделитель столбцов
+	Header     bool      // строка заголовков
+	Quote      QuoteMode // правило заключения значений в кавычки
+	BOM        bool      // метка порядка байтов UTF-8 в начале файла
+	DecimalSep rune      // десятичный разделитель числовых значений (0 - без замены точки)
+}
+
+// Экспорт CSV: разделитель запятая, строка заголовков, кавычки по необходимости.
+func NewCSV() CSV {
+	return CSV{Delimiter: ',', Header: true, Quote: QuoteMinimal}
+}
+
+// Экспорт TSV: разделитель табуляция, строка заголовков, кавычки по необходимости.
+func NewTSV() CSV {
+	return CSV{Delimiter: '\t', Header: true, Quote: QuoteMinimal}
+}
+
+// Расширение файла с точкой.
+func (c CSV) Ext() string {
+	if c.Delimiter == '\t' {
+		return "." + FormatTSV
+	}
+	return "." + FormatCSV
+}
+
+// Запись строк набора с разделителем столбцов. Возвращается количество записанных строк и ошибка.
+//
+// Параметры:
+//
+// w - получатель данных
+// ds - набор данных
+func (c CSV) Export(w io.Writer, ds Dataset) (rows int, err error) {
+
+	// Проверка параметров
+	switch c.Delimiter {
+	case 0:
+		return 0, errors.New("csv -> не задан разделитель столбцов")
+	case '"', '\r', '\n':
+		return 0, errors.New("csv -> недопустимый разделитель столбцов")
+	}
+	if c.DecimalSep == c.Delimiter {
+		return 0, errors.New("csv -> десятичный разделитель совпадает с разделителем столбцов")
+	}
+
+	bw := bufio.NewWriter(w)
+
+	if c.BOM {
+		bw.WriteString("\uFEFF")
+	}
+	if c.Header {
+		c.writeRecord(bw, columns)
+	}
+
+	for row, errRow := range ds.Rows {
+		if errRow != nil {
+			return rows, errSource(rows, errRow)
+		}
+		c.writeRecord(bw, []string{row.Name, c.decimal(row.Value), row.Qual, row.TimeStamp})
+		rows++
+	}
+
+	return rows, bw.Flush()
+}
+
+// Запись одной строки файла.
+func (c CSV) writeRecord(bw *bufio.Writer, record []string) {
+
+	for i, v := range record {
+		if i > 0 {
+			bw.WriteRune(c.Delimiter)
+		}
+		bw.WriteString(c.field(v))
+	}
+	bw.WriteByte('\n')
+}
+
+// Значение столбца по правилу заключения в кавычки.
+func (c CSV) field(v string) string {
+
+	switch c.Quote {
+	case QuoteNone:
+		return v
+	case QuoteMinimal:
+		if !strings.ContainsRune(v, c.Delimiter) && !strings.ContainsAny(v, "\"\r\n") {
+			return v
+		}
+	}
+	return `"` + strings.ReplaceAll(v, `"`, `""`) + `"`
+}
+
+// Замена десятичной точки в числовом значении. Отступы значения сохраняются.
+func (c CSV) decimal(v string) string {
+
+	if c.DecimalSep == 0 || c.DecimalSep == '.' || !strings.Contains(v, ".") {
+		return v
+	}
+	if _, ok := parseNumber(v); !ok {
+		return v
+	}
+	return strings.Replace(v, ".", string(c.DecimalSep), 1)
+}
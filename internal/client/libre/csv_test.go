@@ -0,0 +1,134 @@
+package libre
+
+import (
+	"bytes"
+	clientapi "clienthttps/internal/client/clientAPI"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Тестовый набор строк
+func simDataset() Dataset {
+	data := clientapi.RxDataDB{
+		StartDate: "2025-05-18",
+		Data: []clientapi.DataEl{
+			{Name: "Dev3. HR. Тестовая переменная Word", Value: "    1672", Qual: "1", TimeStamp: "2025-05-18T03:01:02.839697+07:00"},
+			{Name: "Dev1. Real, \"Float\"", Value: "12.5", Qual: "0", TimeStamp: "2025-05-18T03:01:03+07:00"},
+		},
+	}
+	return Dataset{StartDate: data.StartDate, Rows: data.Rows()}
+}
+
+// Экспорт CSV/TSV - параметры записи
+func Test_CSV_Export(t *testing.T) {
+
+	argData := []struct {
+		testName string
+		exp      CSV
+		want     string
+	}{
+		{
+			testName: "csv по умолчанию",
+			exp:      NewCSV(),
+			want: "Name:,Value:,Quality:,TimeStamp:\n" +
+				"Dev3. HR. Тестовая переменная Word,    1672,1,2025-05-18T03:01:02.839697+07:00\n" +
+				"\"Dev1. Real, \"\"Float\"\"\",12.5,0,2025-05-18T03:01:03+07:00\n",
+		},
+		{
+			testName: "tsv по умолчанию",
+			exp:      NewTSV(),
+			want: "Name:\tValue:\tQuality:\tTimeStamp:\n" +
+				"Dev3. HR. Тестовая переменная Word\t    1672\t1\t2025-05-18T03:01:02.839697+07:00\n" +
+				"\"Dev1. Real, \"\"Float\"\"\"\t12.5\t0\t2025-05-18T03:01:03+07:00\n",
+		},
+		{
+			testName: "точка с запятой, десятичная запятая, без заголовка, BOM",
+			exp:      CSV{Delimiter: ';', DecimalSep: ',', BOM: true},
+			want: "\uFEFF" +
+				"Dev3. HR. Тестовая переменная Word;    1672;1;2025-05-18T03:01:02.839697+07:00\n" +
+				"\"Dev1. Real, \"\"Float\"\"\";12,5;0;2025-05-18T03:01:03+07:00\n",
+		},
+		{
+			testName: "все значения в кавычках",
+			exp:      CSV{Delimiter: ',', Quote: QuoteAll},
+			want: "\"Dev3. HR. Тестовая переменная Word\",\"    1672\",\"1\",\"2025-05-18T03:01:02.839697+07:00\"\n" +
+				"\"Dev1. Real, \"\"Float\"\"\",\"12.5\",\"0\",\"2025-05-18T03:01:03+07:00\"\n",
+		},
+		{
+			testName: "без кавычек",
+			exp:      CSV{Delimiter: '\t', Quote: QuoteNone},
+			want: "Dev3. HR. Тестовая переменная Word\t    1672\t1\t2025-05-18T03:01:02.839697+07:00\n" +
+				"Dev1. Real, \"Float\"\t12.5\t0\t2025-05-18T03:01:03+07:00\n",
+		},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := tt.exp.Export(&buf, simDataset())
+			require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+			assert.Equal(t, 2, n)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+// Экспорт CSV - ошибки параметров и источника
+func Test_CSV_Export_Error(t *testing.T) {
+
+	argData := []struct {
+		testName string
+		exp      CSV
+		wantErr  string
+	}{
+		{testName: "нет разделителя", exp: CSV{}, wantErr: "csv -> не задан разделитель столбцов"},
+		{testName: "разделитель кавычка", exp: CSV{Delimiter: '"'}, wantErr: "csv -> недопустимый разделитель столбцов"},
+		{testName: "разделители совпадают", exp: CSV{Delimiter: ',', DecimalSep: ','}, wantErr: "csv -> десятичный разделитель совпадает с разделителем столбцов"},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			_, err := tt.exp.Export(&buf, simDataset())
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+
+	errSrc := errors.New("сбой связи")
+	rows := func(yield func(clientapi.DataEl, error) bool) {
+		if yield(clientapi.DataEl{Name: "Tag"}, nil) {
+			yield(clientapi.DataEl{}, errSrc)
+		}
+	}
+	var buf bytes.Buffer
+	n, err := NewCSV().Export(&buf, Dataset{StartDate: "2025-05-18", Rows: rows})
+	assert.ErrorIs(t, err, errSrc)
+	assert.Equal(t, 1, n)
+}
+
+// Формат экспорта по имени и сохранение в файл
+func Test_NewExporter_Save(t *testing.T) {
+
+	for _, format := range Formats() {
+		t.Run(format, func(t *testing.T) {
+			exp, err := NewExporter(format)
+			require.NoError(t, err)
+			assert.Equal(t, "."+format, exp.Ext())
+
+			fileName, n, err := Save(exp, simDataset())
+			require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+			assert.Equal(t, 2, n)
+			assert.FileExists(t, fileName)
+			require.NoError(t, os.Remove(fileName))
+		})
+	}
+
+	_, err := NewExporter("pdf")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "export -> неизвестный формат: {pdf}")
+}
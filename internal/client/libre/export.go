@@ -0,0 +1,73 @@
+package libre
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Форматы экспорта
+const (
+	FormatXLSX   = "xlsx"
+	FormatCSV    = "csv"
+	FormatTSV    = "tsv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatODS    = "ods"
+)
+
+// Заголовки столбцов архивных данных
+var columns = []string{"Name:", "Value:", "Quality:", "TimeStamp:"}
+
+// Запись набора архивных данных в файл определённого формата.
+type Exporter interface {
+	// Расширение файла с точкой
+	Ext() string
+	// Запись строк набора. Возвращается количество записанных строк и ошибка.
+	Export(w io.Writer, ds Dataset) (rows int, err error)
+}
+
+// Список поддерживаемых форматов экспорта.
+func Formats() []string {
+	return []string{FormatXLSX, FormatODS, FormatCSV, FormatTSV, FormatJSON, FormatNDJSON}
+}
+
+// Формат экспорта по имени с параметрами по умолчанию. Возвращается формат и ошибка.
+//
+// Параметры:
+//
+// format - имя формата (xlsx, ods, csv, tsv, json, ndjson)
+func NewExporter(format string) (Exporter, error) {
+
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case FormatXLSX:
+		return XLSX{}, nil
+	case FormatODS:
+		return ODS{}, nil
+	case FormatCSV:
+		return NewCSV(), nil
+	case FormatTSV:
+		return NewTSV(), nil
+	case FormatJSON:
+		return JSON{}, nil
+	case FormatNDJSON:
+		return JSON{NDJSON: true}, nil
+	}
+	return nil, fmt.Errorf("export -> неизвестный формат: {%s}, доступны: {%s}", format, strings.Join(Formats(), ", "))
+}
+
+// Создание файла экспорта в рабочей директории с именем по шаблону по умолчанию.
+// Возвращается имя файла, количество записанных строк и ошибка. При ошибке файл не создаётся.
+//
+// Параметры:
+//
+// exp - формат экспорта
+// ds - набор данных для сохранения
+func Save(exp Exporter, ds Dataset) (fileName string, rows int, err error) {
+	return Output{}.Save(exp, ds)
+}
+
+// Ошибка источника строк с количеством уже записанных строк.
+func errSource(rows int, err error) error {
+	return fmt.Errorf("ошибка источника строк после {%d} строк: %w", rows, err)
+}
@@ -0,0 +1,86 @@
+package libre
+
+import (
+	"bufio"
+	clientapi "clienthttps/internal/client/clientAPI"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Экспорт в JSON: массив записей или по одной записи в строке (NDJSON).
+type JSON struct {
+	NDJSON bool // по одной записи в строке без обрамляющего массива
+}
+
+// Запись архивных данных с датой экспорта и адресом сервера
+type jsonRecord struct {
+	clientapi.DataEl
+	ExportDate string
+	Server     string
+}
+
+// Расширение файла с точкой.
+func (j JSON) Ext() string {
+	if j.NDJSON {
+		return "." + FormatNDJSON
+	}
+	return "." + FormatJSON
+}
+
+// Запись строк набора в JSON по мере поступления из потока. Возвращается количество записанных строк и ошибка.
+//
+// Параметры:
+//
+// w - получатель данных
+// ds - набор данных
+func (j JSON) Export(w io.Writer, ds Dataset) (rows int, err error) {
+
+	exportDate := ds.ExportDate
+	if exportDate.IsZero() {
+		exportDate = time.Now()
+	}
+
+	rec := jsonRecord{
+		ExportDate: exportDate.Format(time.RFC3339),
+		Server:     ds.Server,
+	}
+
+	bw := bufio.NewWriter(w)
+
+	// Разделители записей
+	open, sep, end := "[\n", ",\n", "\n]\n"
+	if j.NDJSON {
+		open, sep, end = "", "\n", "\n"
+	}
+
+	for row, errRow := range ds.Rows {
+		if errRow != nil {
+			return rows, errSource(rows, errRow)
+		}
+
+		rec.DataEl = row
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return rows, fmt.Errorf("json -> ошибка кодирования строки {%d}: {%v}", rows+1, err)
+		}
+
+		if rows == 0 {
+			bw.WriteString(open)
+		} else {
+			bw.WriteString(sep)
+		}
+		bw.Write(data)
+		rows++
+	}
+
+	switch {
+	case rows > 0:
+		bw.WriteString(end)
+	case !j.NDJSON:
+		bw.WriteString("[]\n")
+	}
+
+	return rows, bw.Flush()
+}
@@ -0,0 +1,69 @@
+package libre
+
+import (
+	"bufio"
+	"bytes"
+	clientapi "clienthttps/internal/client/clientAPI"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Экспорт JSON - массив записей
+func Test_JSON_Export(t *testing.T) {
+
+	ds := simDataset()
+	ds.Server = "https://10.0.0.5:8443"
+	ds.ExportDate = time.Date(2025, 5, 19, 10, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	n, err := JSON{}.Export(&buf, ds)
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, 2, n)
+
+	var got []jsonRecord
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Len(t, got, 2)
+	assert.Equal(t, "Dev3. HR. Тестовая переменная Word", got[0].Name)
+	assert.Equal(t, "12.5", got[1].Value)
+	for _, v := range got {
+		assert.Equal(t, "2025-05-19T10:00:00Z", v.ExportDate)
+		assert.Equal(t, "https://10.0.0.5:8443", v.Server)
+	}
+
+	// Пустой набор - пустой массив
+	buf.Reset()
+	empty := clientapi.RxDataDB{StartDate: "2025-05-18"}
+	n, err = JSON{}.Export(&buf, Dataset{StartDate: empty.StartDate, Rows: empty.Rows()})
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+// Экспорт NDJSON - по одной записи в строке
+func Test_JSON_Export_NDJSON(t *testing.T) {
+
+	ds := simDataset()
+	ds.Server = "https://10.0.0.5:8443"
+
+	var buf bytes.Buffer
+	exp := JSON{NDJSON: true}
+	n, err := exp.Export(&buf, ds)
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, ".ndjson", exp.Ext())
+
+	lines := 0
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var rec jsonRecord
+		require.NoErrorf(t, json.Unmarshal(sc.Bytes(), &rec), "строка {%d}", lines+1)
+		assert.Equal(t, "https://10.0.0.5:8443", rec.Server)
+		assert.NotEmpty(t, rec.ExportDate)
+		lines++
+	}
+	assert.Equal(t, 2, lines)
+}
@@ -2,63 +2,20 @@ package libre
 
 import (
 	clientapi "clienthttps/internal/client/clientAPI"
-	"errors"
-	"fmt"
-	"strings"
+	"iter"
 	"time"
-
-	"github.com/xuri/excelize/v2"
 )
 
-// Создание xlsx файла. Возвращается имя файла и ошибка.
-//
-// Параметры:
-//
-// path - путь к файлу
-// name - имя файла
-// time - время создания файла
-// typ - тип файла
-func createXlsx(path, name, time, typ string) (nameFile string, err error) {
-
-	// Проверка значений аргументов
-	if path == "" {
-		return "", errors.New("создание xlsx -> нет содержимого в path")
-	}
-	if name == "" {
-		return "", errors.New("создание xlsx -> нет содержимого в name")
-	}
-	if time == "" {
-		return "", errors.New("создание xlsx -> нет содержимого в time")
-	}
-	if typ == "" {
-		return "", errors.New("создание xlsx -> нет содержимого в typ")
-	}
-	if !strings.Contains(typ, ".") {
-		return "", errors.New("создание xlsx -> нет точки")
-	}
-	sl := strings.Split(typ, ".")
-	if sl[1] != "xlsx" {
-		return "", errors.New("создание xlsx -> указан тип не xlsx")
-	}
-
-	// Логика
-	file := excelize.NewFile()
-
-	_, err = file.NewSheet("DataDB")
-	if err != nil {
-		return "", errors.New("создание xlsx -> ошибка при добавлении вкладки DataDB")
-	}
-	err = file.DeleteSheet("Sheet1")
-	if err != nil {
-		return "", errors.New("создание xlsx -> ошибка при удалении вкладки Sheet1")
-	}
+// Набор данных для экспорта. Строки передаются потоком и не накапливаются в памяти.
+type Dataset struct {
+	StartDate string                             // начальная дата выгрузки
+	EndDate   string                             // конечная дата выгрузки (пусто для одного дня)
+	Rows      iter.Seq2[clientapi.DataEl, error] // строки архивных данных
 
-	f := path + name + "-" + time + typ
-	err = file.SaveAs(f)
-	if err != nil {
-		return "", fmt.Errorf("создание xlsx -> ошибка при сохранении: {%v} ", err)
-	}
-	return f, nil
+	Server     string    // адрес сервера
+	ExportDate time.Time // дата экспорта (пусто - время записи)
+	TimeStart  string    // время запуска сервера по ответу /status
+	Expected   *int      // количество строк по ответам /cntstr, заполняется к окончанию потока (nil - неизвестно)
 }
 
 // Функция зодаёт xlsx файл и сохраняет туда принятые данные от сервера. Возвращает ошибку.
@@ -69,78 +26,36 @@ func createXlsx(path, name, time, typ string) (nameFile string, err error) {
 // date - дата
 func SaveDataXlsx(data clientapi.RxDataDB) (fileName string, err error) {
 
-	// Проверка аргументов
-	if data.StartDate == "" {
-		return "", errors.New("save xlsx -> нет даты")
-	}
-
-	tn := time.Now().Format("02.01.2006-15:04:05")
-
-	// Создание файла
-	fName := fmt.Sprintf("exportData:%s------------", data.StartDate)
-
-	fileName, err = createXlsx("./", fName, tn, ".xlsx")
-	if err != nil {
-		return "", fmt.Errorf("ошибка при создании xlsx файла экспорта: {%v}", err)
-	}
-
-	// Открытие файла
-	file, err := excelize.OpenFile(fileName)
-	if err != nil {
-		return "", fmt.Errorf("ошибка при открытии файла: {%v}", fileName)
-	}
-
-	// Заполнение файла
-
-	nameSheet := "DataDB"
-
-	// Формирование заголовков
-	// Name: Value:	Quality: TimeStamp:
-	err = file.SetCellValue(nameSheet, "A1", "Name:")
-	if err != nil {
-		return "", errors.New("ошибка при добавлении заголовка столбца Name")
-	}
-	err = file.SetCellValue(nameSheet, "B1", "Value:")
-	if err != nil {
-		return "", errors.New("ошибка при добавлении заголовка столбца Value")
-	}
-	err = file.SetCellValue(nameSheet, "C1", "Quality:")
-	if err != nil {
-		return "", errors.New("ошибка при добавлении заголовка столбца Quality")
-	}
-	err = file.SetCellValue(nameSheet, "D1", "TimeStamp:")
-	if err != nil {
-		return "", errors.New("ошибка при добавлении заголовка столбца TimeStamp")
-	}
-
-	// Перенос данных
-	for i, str := range data.Data {
-
-		i++
-
-		err = file.SetCellValue(nameSheet, fmt.Sprintf("A%d", i+1), str.Name)
-		if err != nil {
-			return "", fmt.Errorf("ошибка добавления значения {%s} в ячейку {A%d}", str.Name, i)
-		}
-		err = file.SetCellValue(nameSheet, fmt.Sprintf("B%d", i+1), str.Value)
-		if err != nil {
-			return "", fmt.Errorf("ошибка добавления значения {%s} в ячейку {B%d}", str.Value, i)
-		}
-		err = file.SetCellValue(nameSheet, fmt.Sprintf("C%d", i+1), str.Qual)
-		if err != nil {
-			return "", fmt.Errorf("ошибка добавления значения {%s} в ячейку {C%d}", str.Qual, i)
-		}
-		err = file.SetCellValue(nameSheet, fmt.Sprintf("D%d", i+1), str.TimeStamp)
-		if err != nil {
-			return "", fmt.Errorf("ошибка добавления значения {%s} в ячейку {D%d}", str.TimeStamp, i)
-		}
-	}
+	fileName, _, err = SaveStreamXlsx(Dataset{
+		StartDate: data.StartDate,
+		EndDate:   data.EndDate,
+		Rows:      data.Rows(),
+	})
+	return fileName, err
+}
 
-	// Сохрангение
-	err = file.Save()
-	if err != nil {
-		return "", errors.New("ошибка при сохранении Xlsx файла")
-	}
+// Функция создаёт ods файл и сохраняет туда принятые данные от сервера.
+// Возвращается имя файла и ошибка.
+//
+// Параметры:
+//
+// data - данные для сохранения
+func SaveDataOds(data clientapi.RxDataDB) (fileName string, err error) {
+
+	fileName, _, err = Save(ODS{}, Dataset{
+		StartDate: data.StartDate,
+		EndDate:   data.EndDate,
+		Rows:      data.Rows(),
+	})
+	return fileName, err
+}
 
-	return fileName, nil
+// Создание xlsx файла и запись в него строк по мере поступления из потока.
+// Возвращается имя файла, количество записанных строк и ошибка. При ошибке потока файл удаляется.
+//
+// Параметры:
+//
+// ds - набор данных для сохранения
+func SaveStreamXlsx(ds Dataset) (fileName string, rows int, err error) {
+	return Save(XLSX{}, ds)
 }
@@ -2,8 +2,10 @@ package libre
 
 import (
 	clientapi "clienthttps/internal/client/clientAPI"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -13,97 +15,6 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
-// Создание файла (успешность)
-func Test_createXlsx_Success(t *testing.T) {
-
-	path := "./"
-	name := "testFile"
-	time := time.Now().Format("02.01.2006-15:04:05")
-	typ := ".xlsx"
-
-	wantName := path + name + "-" + time + typ
-
-	nameFile, err := createXlsx(path, name, time, typ)
-	require.NoErrorf(t, err, "создание файла - ожидалось отсутствие ошибки, а принята: {%s}", fmt.Sprintf("%v", err))
-	assert.Equalf(t, wantName, nameFile, "ожидалось имя файла: {%s}, а принято {%s}", wantName, nameFile)
-
-	_, err = os.Stat(nameFile)
-	require.NoErrorf(t, err, "проверка существоания файла - ожидалось отсутствие ошибки, а принято: {%s}", fmt.Sprintf("%s", err))
-
-	err = os.Remove(nameFile)
-	require.NoErrorf(t, err, "удаление файла - ожидалось отсутствие ошибки, а принято: {%s}", fmt.Sprintf("%s", err))
-}
-
-// Создание файла (ошибки)
-func Test_createXlsx_Error(t *testing.T) {
-
-	argData := []struct {
-		testName string
-		path     string
-		name     string
-		time     string
-		typ      string
-		wantErr  string
-	}{
-		{
-			testName: "пустое содержимое пути",
-			path:     "",
-			name:     "test",
-			time:     "2025-01-01 12:12:12",
-			typ:      ".xlsx",
-			wantErr:  "создание xlsx -> нет содержимого в path",
-		},
-		{
-			testName: "пустое содержимое имени",
-			path:     "./",
-			name:     "",
-			time:     "2025-01-01 12:12:12",
-			typ:      ".xlsx",
-			wantErr:  "создание xlsx -> нет содержимого в name",
-		},
-		{
-			testName: "нет содержимого в time",
-			path:     "./",
-			name:     "test",
-			time:     "",
-			typ:      ".xlsx",
-			wantErr:  "создание xlsx -> нет содержимого в time",
-		},
-		{
-			testName: "нет содержимого в typ",
-			path:     "./",
-			name:     "test",
-			time:     "2025-01-01 12:12:12",
-			typ:      "",
-			wantErr:  "создание xlsx -> нет содержимого в typ",
-		},
-		{
-			testName: "нет точки",
-			path:     "./",
-			name:     "test",
-			time:     "2025-01-01 12:12:12",
-			typ:      "xlsx",
-			wantErr:  "создание xlsx -> нет точки",
-		},
-		{
-			testName: "указан тип не xlsx",
-			path:     "./",
-			name:     "test",
-			time:     "2025-01-01 12:12:12",
-			typ:      ".txt",
-			wantErr:  "создание xlsx -> указан тип не xlsx",
-		},
-	}
-
-	for _, tt := range argData {
-		t.Run(tt.testName, func(t *testing.T) {
-			_, err := createXlsx(tt.path, tt.name, tt.time, tt.typ)
-			rxErr := fmt.Sprintf("%v", err)
-			assert.Equalf(t, tt.wantErr, rxErr, "ошидалась ошибка: {%s}, а принято: {%s}", tt.wantErr, rxErr)
-		})
-	}
-}
-
 // Сохранение данных в xlsx (успешность)
 func Test_SaveDataXlsx_Success(t *testing.T) {
 
@@ -156,13 +67,24 @@ func Test_SaveDataXlsx_Success(t *testing.T) {
 		qual, err := file.GetCellValue(nameSheet, fmt.Sprintf("C%d", i+2))
 		require.NoErrorf(t, err, "чтение qual - ожидалось отсутствие ошибки, а принято: {%s}", fmt.Sprintf("%v", err))
 
-		timeStamp, err := file.GetCellValue(nameSheet, fmt.Sprintf("D%d", i+2))
+		timeStamp, err := file.GetCellValue(nameSheet, fmt.Sprintf("D%d", i+2), excelize.Options{RawCellValue: true})
 		require.NoErrorf(t, err, "чтение timeStamp - ожидалось отсутствие ошибки, а принято: {%s}", fmt.Sprintf("%v", err))
 
 		assert.Equalf(t, v.Name, name, "нет соответствия в name. ожидалось: {%s}, а принято: {%s}", v.Name, name)
 		assert.Equalf(t, v.Value, value, "нет соответствия в value. ожидалось: {%s}, а принято: {%s}", v.Value, value)
 		assert.Equalf(t, v.Qual, qual, "нет соответствия в qual. ожидалось: {%s}, а принято: {%s}", v.Qual, qual)
-		assert.Equalf(t, v.TimeStamp, timeStamp, "нет соответствия в timeStamp. ожидалось: {%s}, а принято: {%s}", v.TimeStamp, timeStamp)
+
+		// Метка времени в формате RFC3339 записывается ячейкой даты по часам пояса метки, иначе - текстом
+		ts, ok := parseTimeStamp(v.TimeStamp)
+		if !ok {
+			assert.Equalf(t, v.TimeStamp, timeStamp, "нет соответствия в timeStamp. ожидалось: {%s}, а принято: {%s}", v.TimeStamp, timeStamp)
+			continue
+		}
+		serial, err := strconv.ParseFloat(timeStamp, 64)
+		require.NoErrorf(t, err, "ячейка timeStamp {%s} не является датой", timeStamp)
+		wall := time.Date(ts.Year(), ts.Month(), ts.Day(), ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), time.UTC)
+		want := float64(wall.Sub(time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC))) / float64(24*time.Hour)
+		assert.InDeltaf(t, want, serial, float64(time.Millisecond)/float64(24*time.Hour), "нет соответствия в timeStamp {%s}", v.TimeStamp)
 	}
 
 }
@@ -193,3 +115,30 @@ func Test_SaveDataXlsx_Error(t *testing.T) {
 		})
 	}
 }
+
+// Сохранение потока строк в xlsx - ошибка источника удаляет файл
+func Test_SaveStreamXlsx_Error(t *testing.T) {
+
+	errSrc := errors.New("сбой связи")
+	rows := func(yield func(clientapi.DataEl, error) bool) {
+		for i := 0; i < 10; i++ {
+			if !yield(clientapi.DataEl{Name: "Tag", Value: strconv.Itoa(i), Qual: "1"}, nil) {
+				return
+			}
+		}
+		yield(clientapi.DataEl{}, errSrc)
+	}
+
+	before, err := filepath.Glob("./*exportData_*")
+	require.NoError(t, err)
+
+	fileName, n, err := SaveStreamXlsx(Dataset{StartDate: "2025-01-01", Rows: rows})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errSrc)
+	assert.Equal(t, 10, n, "строки до ошибки учитываются")
+	assert.Empty(t, fileName)
+
+	after, err := filepath.Glob("./*exportData_*")
+	require.NoError(t, err)
+	assert.Equal(t, before, after, "файл с неполными данными не остаётся")
+}
@@ -0,0 +1,230 @@
+package libre
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Шаблон имени файла экспорта по умолчанию
+const DefaultNameTemplate = "exportData_{range}_{timestamp}"
+
+// Формат метки времени экспорта в имени файла
+const nameTimeFormat = "2006-01-02_15-04-05"
+
+// Символы, недопустимые в именах файлов Windows, FAT и сетевых папок
+const forbiddenNameChars = `<>:"/\|?*`
+
+// Размещение и имя файла экспорта.
+//
+// Подстановки шаблона имени:
+//
+// {server} - имя сервера (адрес и порт)
+// {date} - начальная дата выгрузки
+// {range} - начальная дата или диапазон дат выгрузки
+// {format} - формат файла без точки
+// {timestamp} - дата и время экспорта
+type Output struct {
+	Dir       string // директория файлов экспорта (пусто - рабочая директория)
+	Template  string // шаблон имени файла без расширения (пусто - DefaultNameTemplate)
+	Overwrite bool   // замена существующего файла с тем же именем (false - к имени добавляется номер)
+}
+
+// Имя файла экспорта по шаблону с директорией. Возвращается путь к файлу и ошибка.
+//
+// Параметры:
+//
+// exp - формат экспорта
+// ds - набор данных для сохранения
+func (o Output) FileName(exp Exporter, ds Dataset) (string, error) {
+
+	tmpl := o.Template
+	if tmpl == "" {
+		tmpl = DefaultNameTemplate
+	}
+
+	// Для диапазона дат в имени указываются начальная и конечная даты
+	period := ds.StartDate
+	if ds.EndDate != "" && ds.EndDate != ds.StartDate {
+		period = ds.StartDate + "--" + ds.EndDate
+	}
+
+	values := map[string]string{
+		"server":    serverName(ds.Server),
+		"date":      ds.StartDate,
+		"range":     period,
+		"format":    strings.TrimPrefix(exp.Ext(), "."),
+		"timestamp": ds.ExportDate.Format(nameTimeFormat),
+	}
+
+	var sb strings.Builder
+	for rest := tmpl; rest != ""; {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			sb.WriteString(rest)
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("name -> не закрыта подстановка в шаблоне имени: {%s}", tmpl)
+		}
+		key := rest[start+1 : start+end]
+		v, ok := values[key]
+		if !ok {
+			return "", fmt.Errorf("name -> неизвестная подстановка {%s} в шаблоне имени: {%s}", key, tmpl)
+		}
+		sb.WriteString(rest[:start])
+		sb.WriteString(v)
+		rest = rest[start+end+1:]
+	}
+
+	name := safeFileName(sb.String())
+	if name == "" {
+		return "", fmt.Errorf("name -> пустое имя файла по шаблону: {%s}", tmpl)
+	}
+
+	return filepath.Join(o.Dir, name+exp.Ext()), nil
+}
+
+// Создание файла экспорта и запись в него строк набора данных по мере поступления.
+// Строки пишутся во временный файл той же директории, который после успешной записи
+// переименовывается в файл экспорта: прерванная выгрузка не оставляет недописанный файл.
+// Существующий файл с тем же именем заменяется только при Overwrite.
+// Возвращается имя файла, количество записанных строк и ошибка. При ошибке временный файл удаляется.
+//
+// Параметры:
+//
+// exp - формат экспорта
+// ds - набор данных для сохранения
+func (o Output) Save(exp Exporter, ds Dataset) (fileName string, rows int, err error) {
+
+	prefix := "save " + strings.TrimPrefix(exp.Ext(), ".")
+
+	// Проверка аргументов
+	if ds.StartDate == "" {
+		return "", 0, errors.New(prefix + " -> нет даты")
+	}
+	if ds.Rows == nil {
+		return "", 0, errors.New(prefix + " -> нет источника строк")
+	}
+
+	if ds.ExportDate.IsZero() {
+		ds.ExportDate = time.Now()
+	}
+
+	fileName, err = o.FileName(exp, ds)
+	if err != nil {
+		return "", 0, fmt.Errorf("%s -> %w", prefix, err)
+	}
+
+	if o.Dir != "" {
+		err = os.MkdirAll(o.Dir, 0o755)
+		if err != nil {
+			return "", 0, fmt.Errorf("%s -> ошибка при создании директории: {%v}", prefix, err)
+		}
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(fileName), "."+filepath.Base(fileName)+".*.tmp")
+	if err != nil {
+		return "", 0, fmt.Errorf("%s -> ошибка при создании файла: {%v}", prefix, err)
+	}
+	tmpName := file.Name()
+
+	bw := bufio.NewWriter(file)
+	rows, err = exp.Export(bw, ds)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	errClose := file.Close()
+	if err == nil && errClose != nil {
+		err = fmt.Errorf("ошибка при закрытии файла: {%v}", errClose)
+	}
+	if err == nil {
+		err = os.Chmod(tmpName, 0o644)
+	}
+	if err == nil {
+		fileName, err = o.place(tmpName, fileName)
+	}
+	if err != nil {
+		os.Remove(tmpName)
+		return "", rows, fmt.Errorf("%s -> %w", prefix, err)
+	}
+
+	return fileName, rows, nil
+}
+
+// Перемещение записанного временного файла в файл экспорта. Без Overwrite существующий файл не заменяется,
+// к имени добавляется номер: name_2.ext, name_3.ext ... Имя занимается созданием пустого файла,
+// поэтому одновременные экспорты не заменяют файлы друг друга. Возвращается имя файла и ошибка.
+//
+// Параметры:
+//
+// tmpName - временный файл
+// fileName - файл экспорта по шаблону
+func (o Output) place(tmpName, fileName string) (string, error) {
+
+	if o.Overwrite {
+		return fileName, os.Rename(tmpName, fileName)
+	}
+
+	ext := filepath.Ext(fileName)
+	base := strings.TrimSuffix(fileName, ext)
+	name := fileName
+	for i := 2; ; i++ {
+		file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			file.Close()
+			err = os.Rename(tmpName, name)
+			if err != nil {
+				os.Remove(name)
+				return "", err
+			}
+			return name, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("ошибка при создании файла: {%v}", err)
+		}
+		name = fmt.Sprintf("%s_%d%s", base, i, ext)
+	}
+}
+
+// Имя сервера для имени файла: адрес и порт без схемы.
+//
+// Параметры:
+//
+// server - адрес сервера
+func serverName(server string) string {
+
+	u, err := url.Parse(server)
+	if err == nil && u.Host != "" {
+		return u.Host
+	}
+	return server
+}
+
+// Замена недопустимых в имени файла символов на '_'. Пробелы и точки в конце имени удаляются.
+//
+// Параметры:
+//
+// name - имя файла
+func safeFileName(name string) string {
+
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(forbiddenNameChars, r) {
+			return '_'
+		}
+		return r
+	}, name)
+
+	return strings.TrimRight(strings.TrimSpace(name), ". ")
+}
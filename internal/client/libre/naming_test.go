@@ -0,0 +1,166 @@
+package libre
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Имя файла экспорта по шаблону
+func Test_Output_FileName(t *testing.T) {
+
+	ds := Dataset{
+		StartDate:  "2025-05-18",
+		EndDate:    "2025-05-20",
+		Server:     "https://192.168.1.10:8443",
+		ExportDate: time.Date(2025, 5, 21, 14, 3, 9, 0, time.UTC),
+	}
+
+	argData := []struct {
+		testName string
+		out      Output
+		exp      Exporter
+		ds       Dataset
+		want     string
+		wantErr  string
+	}{
+		{
+			testName: "шаблон по умолчанию",
+			exp:      XLSX{},
+			ds:       ds,
+			want:     "exportData_2025-05-18--2025-05-20_2025-05-21_14-03-09.xlsx",
+		},
+		{
+			testName: "один день",
+			exp:      NewCSV(),
+			ds:       Dataset{StartDate: "2025-05-18", EndDate: "2025-05-18", ExportDate: ds.ExportDate},
+			want:     "exportData_2025-05-18_2025-05-21_14-03-09.csv",
+		},
+		{
+			testName: "все подстановки и директория",
+			out:      Output{Dir: "out", Template: "{server}_{date}_{range}_{format}_{timestamp}"},
+			exp:      JSON{NDJSON: true},
+			ds:       ds,
+			want:     filepath.Join("out", "192.168.1.10_8443_2025-05-18_2025-05-18--2025-05-20_ndjson_2025-05-21_14-03-09.ndjson"),
+		},
+		{
+			testName: "недопустимые символы",
+			out:      Output{Template: `a:b/c\d*e?f"g<h>i|j. `},
+			exp:      ODS{},
+			ds:       ds,
+			want:     "a_b_c_d_e_f_g_h_i_j.ods",
+		},
+		{
+			testName: "неизвестная подстановка",
+			out:      Output{Template: "{host}"},
+			exp:      XLSX{},
+			ds:       ds,
+			wantErr:  "name -> неизвестная подстановка {host} в шаблоне имени: {{host}}",
+		},
+		{
+			testName: "не закрыта подстановка",
+			out:      Output{Template: "data_{date"},
+			exp:      XLSX{},
+			ds:       ds,
+			wantErr:  "name -> не закрыта подстановка в шаблоне имени: {data_{date}",
+		},
+		{
+			testName: "пустое имя",
+			out:      Output{Template: " . "},
+			exp:      XLSX{},
+			ds:       ds,
+			wantErr:  "name -> пустое имя файла по шаблону: { . }",
+		},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+
+			got, err := tt.out.FileName(tt.exp, tt.ds)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+			assert.Equal(t, tt.want, got)
+			assert.NotContains(t, filepath.Base(got), ":")
+		})
+	}
+}
+
+// Запись, прерванная после части данных
+type failingExporter struct{ err error }
+
+func (f failingExporter) Ext() string { return ".csv" }
+
+func (f failingExporter) Export(w io.Writer, ds Dataset) (int, error) {
+	w.Write(bytes.Repeat([]byte("x"), 64*1024))
+	return 1, f.err
+}
+
+// Сохранение в директорию через временный файл
+func Test_Output_Save(t *testing.T) {
+
+	dir := filepath.Join(t.TempDir(), "export", "day")
+	out := Output{Dir: dir, Template: "{date}_{format}"}
+
+	// Директория создаётся, временный файл переименовывается в файл экспорта
+	fileName, n, err := out.Save(NewCSV(), simDataset())
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, filepath.Join(dir, "2025-05-18_csv.csv"), fileName)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "временный файл не остаётся")
+	assert.Equal(t, "2025-05-18_csv.csv", entries[0].Name())
+
+	data, err := os.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "Name:"))
+
+	// Прерванная запись не заменяет существующий файл и не оставляет временный
+	errSrc := errors.New("сбой связи")
+	_, _, err = out.Save(failingExporter{err: errSrc}, simDataset())
+	assert.ErrorIs(t, err, errSrc)
+
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	after, err := os.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, data, after, "файл предыдущего экспорта не изменён")
+
+	// Повторный экспорт с тем же именем не заменяет существующий файл
+	for _, want := range []string{"2025-05-18_csv_2.csv", "2025-05-18_csv_3.csv"} {
+		name, _, err := out.Save(NewCSV(), simDataset())
+		require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+		assert.Equal(t, filepath.Join(dir, want), name)
+	}
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	// Замена существующего файла по запросу
+	err = os.WriteFile(fileName, []byte("old"), 0o644)
+	require.NoError(t, err)
+	out.Overwrite = true
+	name, _, err := out.Save(NewCSV(), simDataset())
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, fileName, name)
+	after, err = os.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, data, after, "файл заменён")
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
@@ -0,0 +1,231 @@
+package libre
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Экспорт в книгу OpenDocument (ods) для LibreOffice Calc.
+// Числовые и логические значения записываются ячейками соответствующего типа, метки времени - ячейками даты.
+// Формат отображения даты фиксирован: ГГГГ-ММ-ДД чч:мм:сс.000, CellFormat.TimeFormat не используется.
+type ODS struct {
+	Cells CellFormat // параметры записи значений в ячейки
+}
+
+// Тип файла OpenDocument
+const odsMimeType = "application/vnd.oasis.opendocument.spreadsheet"
+
+// Описание содержимого книги
+const odsManifest = xml.Header + `<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+ <manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="` + odsMimeType + `"/>
+ <manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>
+`
+
+// Начало содержимого книги с форматом ячеек даты
+const odsContentHead = xml.Header + `<office:document-content` +
+	` xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"` +
+	` xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0"` +
+	` xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"` +
+	` xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"` +
+	` xmlns:number="urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0"` +
+	` office:version="1.2">
+<office:automatic-styles>
+ <number:date-style style:name="N1">
+  <number:year number:style="long"/><number:text>-</number:text><number:month number:style="long"/><number:text>-</number:text><number:day number:style="long"/>
+  <number:text> </number:text>
+  <number:hours number:style="long"/><number:text>:</number:text><number:minutes number:style="long"/><number:text>:</number:text><number:seconds number:style="long" number:decimal-places="3"/>
+ </number:date-style>
+ <style:style style:name="ce1" style:family="table-cell" style:data-style-name="N1"/>
+</office:automatic-styles>
+<office:body>
+<office:spreadsheet>
+`
+
+// Окончание содержимого книги
+const odsContentTail = `</office:spreadsheet>
+</office:body>
+</office:document-content>
+`
+
+// Расширение файла с точкой.
+func (ODS) Ext() string {
+	return "." + FormatODS
+}
+
+// Запись строк набора в книгу ods за один проход по мере поступления из потока.
+// Раскладка листов совпадает с xlsx: DataDB, при заполнении листа - DataDB_2, DataDB_3 ..., в конце - Summary.
+// Возвращается количество записанных строк и ошибка.
+//
+// Параметры:
+//
+// w - получатель книги
+// ds - набор данных
+func (o ODS) Export(w io.Writer, ds Dataset) (rows int, err error) {
+
+	zw := zip.NewWriter(w)
+
+	// Тип файла записывается первым и без сжатия
+	mt, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return 0, fmt.Errorf("ods -> ошибка записи mimetype: {%v}", err)
+	}
+	_, err = io.WriteString(mt, odsMimeType)
+	if err != nil {
+		return 0, fmt.Errorf("ods -> ошибка записи mimetype: {%v}", err)
+	}
+
+	mf, err := zw.Create("META-INF/manifest.xml")
+	if err != nil {
+		return 0, fmt.Errorf("ods -> ошибка записи manifest.xml: {%v}", err)
+	}
+	_, err = io.WriteString(mf, odsManifest)
+	if err != nil {
+		return 0, fmt.Errorf("ods -> ошибка записи manifest.xml: {%v}", err)
+	}
+
+	cw, err := zw.Create("content.xml")
+	if err != nil {
+		return 0, fmt.Errorf("ods -> ошибка записи content.xml: {%v}", err)
+	}
+	bw := bufio.NewWriter(cw)
+	bw.WriteString(odsContentHead)
+
+	summary := newSummaryBuilder()
+
+	// Листы с заголовками
+	sheet, sheetRow := 0, sheetRows
+	for str, errRow := range ds.Rows {
+
+		if errRow != nil {
+			return rows, errSource(rows, errRow)
+		}
+
+		if sheetRow >= sheetRows {
+			if sheet > 0 {
+				bw.WriteString("</table:table>\n")
+			}
+			sheet++
+			o.openSheet(bw, sheet)
+			sheetRow = 1
+		}
+
+		bw.WriteString("<table:table-row>")
+		o.textCell(bw, str.Name)
+		o.cell(bw, o.Cells.value(str.Name, str.Value), str.Value)
+		o.cell(bw, o.Cells.quality(str.Qual), str.Qual)
+		o.cell(bw, o.Cells.timeStamp(str.TimeStamp), str.TimeStamp)
+		bw.WriteString("</table:table-row>\n")
+
+		sheetRow++
+		rows++
+		summary.add(str)
+	}
+	summary.done()
+
+	if sheet == 0 {
+		o.openSheet(bw, 1)
+	}
+	bw.WriteString("</table:table>\n")
+
+	o.summarySheet(bw, summary.rows(ds, rows, o.Cells))
+	bw.WriteString(odsContentTail)
+
+	err = bw.Flush()
+	if err != nil {
+		return rows, fmt.Errorf("ods -> ошибка записи content.xml: {%v}", err)
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return rows, fmt.Errorf("ods -> ошибка завершения книги: {%v}", err)
+	}
+	return rows, nil
+}
+
+// Начало листа с номером num и строка заголовков.
+func (o ODS) openSheet(bw *bufio.Writer, num int) {
+
+	name := "DataDB"
+	if num > 1 {
+		name = fmt.Sprintf("%s_%d", name, num)
+	}
+
+	bw.WriteString(`<table:table table:name="` + name + `">`)
+	bw.WriteString("\n<table:table-row>")
+	for _, v := range columns {
+		o.textCell(bw, v)
+	}
+	bw.WriteString("</table:table-row>\n")
+}
+
+// Лист сводки Summary.
+func (o ODS) summarySheet(bw *bufio.Writer, rows [][]any) {
+
+	bw.WriteString(`<table:table table:name="` + summarySheet + `">` + "\n")
+	for _, row := range rows {
+		bw.WriteString("<table:table-row>")
+		for _, v := range row {
+			switch val := v.(type) {
+			case nil:
+				bw.WriteString("<table:table-cell/>")
+			case int:
+				o.cell(bw, float64(val), strconv.Itoa(val))
+			case float64:
+				o.cell(bw, val, strconv.FormatFloat(val, 'g', 6, 64))
+			case time.Time:
+				o.cell(bw, val, val.Format(time.RFC3339Nano))
+			default:
+				o.textCell(bw, fmt.Sprint(val))
+			}
+		}
+		if len(row) == 0 {
+			bw.WriteString("<table:table-cell/>")
+		}
+		bw.WriteString("</table:table-row>\n")
+	}
+	bw.WriteString("</table:table>\n")
+}
+
+// Текстовая ячейка.
+func (ODS) textCell(bw *bufio.Writer, v string) {
+
+	bw.WriteString(`<table:table-cell office:value-type="string"><text:p>`)
+	xml.EscapeText(bw, []byte(v))
+	bw.WriteString("</text:p></table:table-cell>")
+}
+
+// Ячейка по типу значения: число, логическое значение, дата или текст.
+//
+// Параметры:
+//
+// bw - получатель содержимого
+// val - значение ячейки
+// text - отображаемый текст ячейки
+func (o ODS) cell(bw *bufio.Writer, val any, text string) {
+
+	switch v := val.(type) {
+	case float64:
+		bw.WriteString(`<table:table-cell office:value-type="float" office:value="`)
+		bw.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	case bool:
+		bw.WriteString(`<table:table-cell office:value-type="boolean" office:boolean-value="`)
+		bw.WriteString(strconv.FormatBool(v))
+	case time.Time:
+		// Время записывается без часового пояса, по часам пояса отображения
+		bw.WriteString(`<table:table-cell table:style-name="ce1" office:value-type="date" office:date-value="`)
+		bw.WriteString(v.Format("2006-01-02T15:04:05.999999999"))
+	default:
+		o.textCell(bw, text)
+		return
+	}
+
+	bw.WriteString(`"><text:p>`)
+	xml.EscapeText(bw, []byte(text))
+	bw.WriteString("</text:p></table:table-cell>")
+}
@@ -0,0 +1,150 @@
+package libre
+
+import (
+	"archive/zip"
+	"bytes"
+	clientapi "clienthttps/internal/client/clientAPI"
+	"encoding/xml"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Ячейка содержимого ods для проверки
+type odsCell struct {
+	Type      string `xml:"value-type,attr"`
+	Value     string `xml:"value,attr"`
+	DateValue string `xml:"date-value,attr"`
+	Text      string `xml:"p"`
+}
+
+// Лист содержимого ods для проверки
+type odsTable struct {
+	Name string `xml:"name,attr"`
+	Rows []struct {
+		Cells []odsCell `xml:"table-cell"`
+	} `xml:"table-row"`
+}
+
+// Чтение листов книги ods.
+func readOds(t *testing.T, data []byte) []odsTable {
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.NotEmpty(t, zr.File)
+
+	// Тип файла - первый и без сжатия
+	assert.Equal(t, "mimetype", zr.File[0].Name)
+	assert.Equal(t, zip.Store, zr.File[0].Method)
+
+	for _, f := range zr.File {
+		if f.Name != "content.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		require.NoError(t, err)
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+
+		var doc struct {
+			Tables []odsTable `xml:"body>spreadsheet>table"`
+		}
+		require.NoError(t, xml.Unmarshal(content, &doc))
+		return doc.Tables
+	}
+
+	t.Fatal("в книге нет content.xml")
+	return nil
+}
+
+// Экспорт ods - раскладка листа и типы ячеек
+func Test_ODS_Export(t *testing.T) {
+
+	data := clientapi.RxDataDB{
+		StartDate: "2025-05-18",
+		Data: []clientapi.DataEl{
+			{Name: "Dev3. HR. Тестовая переменная Word", Value: "    1672", Qual: "1", TimeStamp: "2025-05-18T03:01:02.84024+07:00"},
+			{Name: "Dev1. <Real> & Float", Value: "ошибка", Qual: "0", TimeStamp: "нет времени"},
+			{Name: "Dev1. <Real> & Float", Value: "NaN", Qual: "0", TimeStamp: "нет времени"},
+		},
+	}
+
+	var buf bytes.Buffer
+	n, err := ODS{}.Export(&buf, Dataset{StartDate: data.StartDate, Rows: data.Rows()})
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, 3, n)
+
+	tables := readOds(t, buf.Bytes())
+	require.Len(t, tables, 2)
+	assert.Equal(t, "DataDB", tables[0].Name)
+	assert.Equal(t, "Summary", tables[1].Name)
+	require.Len(t, tables[0].Rows, 4)
+
+	header := tables[0].Rows[0].Cells
+	require.Len(t, header, 4)
+	for i, v := range columns {
+		assert.Equal(t, v, header[i].Text)
+	}
+
+	row := tables[0].Rows[1].Cells
+	assert.Equal(t, odsCell{Type: "string", Text: "Dev3. HR. Тестовая переменная Word"}, row[0])
+	assert.Equal(t, odsCell{Type: "float", Value: "1672", Text: "    1672"}, row[1])
+	assert.Equal(t, odsCell{Type: "float", Value: "1", Text: "1"}, row[2])
+	assert.Equal(t, odsCell{Type: "date", DateValue: "2025-05-18T03:01:02.84024", Text: "2025-05-18T03:01:02.84024+07:00"}, row[3])
+
+	// Значения, которые не разбираются, записываются текстом
+	row = tables[0].Rows[2].Cells
+	assert.Equal(t, odsCell{Type: "string", Text: "Dev1. <Real> & Float"}, row[0])
+	assert.Equal(t, odsCell{Type: "string", Text: "ошибка"}, row[1])
+	assert.Equal(t, odsCell{Type: "string", Text: "нет времени"}, row[3])
+	assert.Equal(t, odsCell{Type: "string", Text: "NaN"}, tables[0].Rows[3].Cells[1])
+}
+
+// Экспорт ods - переход на следующий лист и пустой набор
+func Test_ODS_Export_Sheets(t *testing.T) {
+
+	limit := sheetRows
+	sheetRows = 3
+	t.Cleanup(func() { sheetRows = limit })
+
+	data := clientapi.RxDataDB{StartDate: "2025-05-18", Data: make([]clientapi.DataEl, 5)}
+
+	var buf bytes.Buffer
+	_, err := ODS{}.Export(&buf, Dataset{StartDate: data.StartDate, Rows: data.Rows()})
+	require.NoError(t, err)
+
+	tables := readOds(t, buf.Bytes())
+	require.Len(t, tables, 4)
+	assert.Equal(t, "DataDB_3", tables[2].Name)
+	assert.Len(t, tables[0].Rows, 3)
+	assert.Len(t, tables[2].Rows, 2)
+
+	// Пустой набор - лист с заголовками
+	buf.Reset()
+	data.Data = nil
+	_, err = ODS{}.Export(&buf, Dataset{StartDate: data.StartDate, Rows: data.Rows()})
+	require.NoError(t, err)
+	tables = readOds(t, buf.Bytes())
+	require.Len(t, tables, 2)
+	assert.Len(t, tables[0].Rows, 1)
+}
+
+// Сохранение данных в ods
+func Test_SaveDataOds(t *testing.T) {
+
+	data := clientapi.RxDataDB{StartDate: "2025-05-18", Data: make([]clientapi.DataEl, 3)}
+
+	fileName, err := SaveDataOds(data)
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	defer os.Remove(fileName)
+
+	content, err := os.ReadFile(fileName)
+	require.NoError(t, err)
+	tables := readOds(t, content)
+	require.Len(t, tables, 2)
+	assert.Len(t, tables[0].Rows, 4)
+}
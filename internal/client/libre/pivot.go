@@ -0,0 +1,204 @@
+package libre
+
+import (
+	clientapi "clienthttps/internal/client/clientAPI"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Размещение значений качества в листе широкого формата
+type QualityMode int
+
+const (
+	QualityColumn QualityMode = iota // столбец качества рядом со столбцом значения переменной
+	QualitySheet                     // отдельный лист PivotQual с той же раскладкой
+	QualityNone                      // качество не записывается
+)
+
+// Имена листов широкого формата и качества
+const (
+	pivotSheet     = "Pivot"
+	pivotQualSheet = "PivotQual"
+)
+
+// Параметры листа широкого формата Pivot: одна строка на метку времени, один столбец на переменную.
+// Метки времени, которые не разбираются, в лист не попадают.
+type Pivot struct {
+	Tolerance time.Duration // метки в пределах допуска от первой метки строки объединяются в одну строку
+	Interval  time.Duration // интервал передискретизации, в строку попадает последнее значение интервала (0 - без передискретизации)
+	Quality   QualityMode   // размещение значений качества
+}
+
+// Значение переменной с меткой времени
+type pivotSample struct {
+	t   time.Time
+	tag int
+	row clientapi.DataEl
+}
+
+// Сборка листа широкого формата. Строки накапливаются в памяти до записи листа.
+type pivotBuilder struct {
+	opts    Pivot
+	tags    []string       // имена переменных по порядку первого появления
+	index   map[string]int // номер столбца переменной
+	samples []pivotSample  // значения переменных
+}
+
+// Создание сборки листа широкого формата.
+func newPivotBuilder(opts Pivot) *pivotBuilder {
+	return &pivotBuilder{opts: opts, index: make(map[string]int)}
+}
+
+// Добавление строки архивных данных.
+func (p *pivotBuilder) add(row clientapi.DataEl) {
+
+	t, ok := parseTimeStamp(row.TimeStamp)
+	if !ok {
+		return
+	}
+
+	tag, ok := p.index[row.Name]
+	if !ok {
+		tag = len(p.tags)
+		p.index[row.Name] = tag
+		p.tags = append(p.tags, row.Name)
+	}
+
+	p.samples = append(p.samples, pivotSample{t: t, tag: tag, row: row})
+}
+
+// Строка широкого формата: метка времени строки и последнее значение каждой переменной
+type pivotRow struct {
+	t    time.Time
+	vals []*clientapi.DataEl
+}
+
+// Группировка значений в строки по метке времени с учётом интервала или допуска.
+func (p *pivotBuilder) rows() []pivotRow {
+
+	slices.SortStableFunc(p.samples, func(a, b pivotSample) int {
+		return a.t.Compare(b.t)
+	})
+
+	rows := make([]pivotRow, 0)
+	for i := range p.samples {
+		s := &p.samples[i]
+
+		// Метка времени строки для значения
+		key := s.t
+		if p.opts.Interval > 0 {
+			key = s.t.Truncate(p.opts.Interval)
+		}
+
+		last := len(rows) - 1
+		switch {
+		case last < 0:
+		case p.opts.Interval > 0 && rows[last].t.Equal(key):
+			rows[last].vals[s.tag] = &s.row
+			continue
+		case p.opts.Interval <= 0 && s.t.Sub(rows[last].t) <= p.opts.Tolerance:
+			rows[last].vals[s.tag] = &s.row
+			continue
+		}
+
+		r := pivotRow{t: key, vals: make([]*clientapi.DataEl, len(p.tags))}
+		r.vals[s.tag] = &s.row
+		rows = append(rows, r)
+	}
+
+	return rows
+}
+
+// Запись листа Pivot и, при размещении качества на отдельном листе, листа PivotQual.
+//
+// Параметры:
+//
+// file - книга
+// cf - параметры записи значений в ячейки
+// timeStyle - стиль ячеек времени
+func (p *pivotBuilder) write(file *excelize.File, cf CellFormat, timeStyle int) error {
+
+	if len(p.tags)+1 > excelize.MaxColumns/2 {
+		return fmt.Errorf("xlsx -> переменных {%d} больше, чем вмещает лист широкого формата", len(p.tags))
+	}
+
+	rows := p.rows()
+
+	// Лист значений
+	header := []any{"TimeStamp:"}
+	for _, tag := range p.tags {
+		header = append(header, tag)
+		if p.opts.Quality == QualityColumn {
+			header = append(header, tag+" Quality:")
+		}
+	}
+
+	width := 1
+	if p.opts.Quality == QualityColumn {
+		width = 2
+	}
+
+	err := p.writeSheet(file, pivotSheet, header, rows, timeStyle, cf, width, func(el *clientapi.DataEl) []any {
+		if p.opts.Quality != QualityColumn {
+			return []any{cf.value(el.Name, el.Value)}
+		}
+		return []any{cf.value(el.Name, el.Value), cf.quality(el.Qual)}
+	})
+	if err != nil || p.opts.Quality != QualitySheet {
+		return err
+	}
+
+	// Лист качества с той же раскладкой
+	return p.writeSheet(file, pivotQualSheet, header, rows, timeStyle, cf, 1, func(el *clientapi.DataEl) []any {
+		return []any{cf.quality(el.Qual)}
+	})
+}
+
+// Запись строк широкого формата в лист.
+//
+// Параметры:
+//
+// file - книга
+// base - имя листа
+// header - заголовки столбцов
+// rows - строки широкого формата
+// timeStyle - стиль ячеек времени
+// cf - параметры записи значений в ячейки
+// width - количество столбцов одной переменной
+// cells - ячейки одной переменной
+func (p *pivotBuilder) writeSheet(file *excelize.File, base string, header []any, rows []pivotRow, timeStyle int, cf CellFormat,
+	width int, cells func(el *clientapi.DataEl) []any) error {
+
+	sheets, err := newSheetWriter(file, base, header, false)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+
+		t := r.t
+		if cf.Location != nil {
+			t = t.In(cf.Location)
+		}
+
+		values := make([]any, 0, len(header))
+		values = append(values, excelize.Cell{StyleID: timeStyle, Value: t})
+		for _, el := range r.vals {
+			if el == nil {
+				values = append(values, make([]any, width)...)
+				continue
+			}
+			values = append(values, cells(el)...)
+		}
+
+		err = sheets.add(values)
+		if err != nil {
+			return err
+		}
+	}
+
+	return sheets.flush()
+}
@@ -0,0 +1,140 @@
+package libre
+
+import (
+	"bytes"
+	clientapi "clienthttps/internal/client/clientAPI"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// Архивные данные двух переменных с расхождением меток в пределах 5 мс
+func simPivotData() clientapi.RxDataDB {
+	return clientapi.RxDataDB{
+		StartDate: "2025-05-18",
+		Data: []clientapi.DataEl{
+			{Name: "Dev3. HR. Word", Value: "10", Qual: "1", TimeStamp: "2025-05-18T03:01:02.840+07:00"},
+			{Name: "Dev2. Coil. Bool", Value: "1", Qual: "1", TimeStamp: "2025-05-18T03:01:02.839+07:00"},
+			{Name: "Dev3. HR. Word", Value: "11", Qual: "0", TimeStamp: "2025-05-18T03:01:07.840+07:00"},
+			{Name: "Dev2. Coil. Bool", Value: "0", Qual: "1", TimeStamp: "2025-05-18T03:01:07.843+07:00"},
+			{Name: "Dev3. HR. Word", Value: "12", Qual: "1", TimeStamp: "2025-05-18T03:01:12.840+07:00"},
+			{Name: "Dev3. HR. Word", Value: "13", Qual: "1", TimeStamp: "нет времени"},
+		},
+	}
+}
+
+// Группировка значений в строки широкого формата
+func Test_pivotBuilder_rows(t *testing.T) {
+
+	argData := []struct {
+		testName string
+		opts     Pivot
+		want     [][]string // значения переменных по строкам, "-" - нет значения
+	}{
+		{
+			testName: "точное совпадение меток",
+			opts:     Pivot{},
+			want:     [][]string{{"-", "1"}, {"10", "-"}, {"11", "-"}, {"-", "0"}, {"12", "-"}},
+		},
+		{
+			testName: "допуск 5 мс",
+			opts:     Pivot{Tolerance: 5 * time.Millisecond},
+			want:     [][]string{{"10", "1"}, {"11", "0"}, {"12", "-"}},
+		},
+		{
+			testName: "интервал 10 с - последнее значение",
+			opts:     Pivot{Interval: 10 * time.Second},
+			want:     [][]string{{"11", "0"}, {"12", "-"}},
+		},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+
+			p := newPivotBuilder(tt.opts)
+			for _, row := range simPivotData().Data {
+				p.add(row)
+			}
+			require.Equal(t, []string{"Dev3. HR. Word", "Dev2. Coil. Bool"}, p.tags)
+
+			rows := p.rows()
+			got := make([][]string, 0, len(rows))
+			for _, r := range rows {
+				vals := make([]string, 0, len(r.vals))
+				for _, el := range r.vals {
+					if el == nil {
+						vals = append(vals, "-")
+						continue
+					}
+					vals = append(vals, el.Value)
+				}
+				got = append(got, vals)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// Экспорт xlsx с листом широкого формата
+func Test_XLSX_Export_Pivot(t *testing.T) {
+
+	argData := []struct {
+		testName   string
+		quality    QualityMode
+		wantSheets []string
+		wantHeader []string
+		wantRow    []string
+	}{
+		{
+			testName:   "качество в столбце",
+			quality:    QualityColumn,
+			wantSheets: []string{"DataDB", "Pivot", "Summary"},
+			wantHeader: []string{"TimeStamp:", "Dev3. HR. Word", "Dev3. HR. Word Quality:", "Dev2. Coil. Bool", "Dev2. Coil. Bool Quality:"},
+			wantRow:    []string{"2025-05-18 03:01", "11", "0", "FALSE", "1"},
+		},
+		{
+			testName:   "качество на листе",
+			quality:    QualitySheet,
+			wantSheets: []string{"DataDB", "Pivot", "PivotQual", "Summary"},
+			wantHeader: []string{"TimeStamp:", "Dev3. HR. Word", "Dev2. Coil. Bool"},
+			wantRow:    []string{"2025-05-18 03:01", "11", "FALSE"},
+		},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+
+			data := simPivotData()
+			exp := XLSX{
+				Cells: CellFormat{TimeFormat: "yyyy-mm-dd hh:mm"},
+				Pivot: &Pivot{Tolerance: 5 * time.Millisecond, Quality: tt.quality},
+			}
+
+			var buf bytes.Buffer
+			n, err := exp.Export(&buf, Dataset{StartDate: data.StartDate, Rows: data.Rows()})
+			require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+			assert.Equal(t, len(data.Data), n, "в DataDB записываются все строки")
+
+			file, err := excelize.OpenReader(&buf)
+			require.NoError(t, err)
+			defer file.Close()
+			assert.Equal(t, tt.wantSheets, file.GetSheetList())
+
+			rows, err := file.GetRows("Pivot")
+			require.NoError(t, err)
+			require.Len(t, rows, 4)
+			assert.Equal(t, tt.wantHeader, rows[0])
+			assert.Equal(t, tt.wantRow, rows[2])
+
+			if tt.quality == QualitySheet {
+				rows, err = file.GetRows("PivotQual")
+				require.NoError(t, err)
+				require.Len(t, rows, 4)
+				assert.Equal(t, []string{"2025-05-18 03:01", "0", "1"}, rows[2])
+			}
+		})
+	}
+}
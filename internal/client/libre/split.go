@@ -0,0 +1,188 @@
+package libre
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Разделение строк книги по листам
+type SplitMode int
+
+const (
+	SplitNone   SplitMode = iota // все строки на листе DataDB
+	SplitDevice                  // лист на устройство: префикс имени до первой точки (Dev1, Dev2 ...)
+	SplitTag                     // лист на переменную: полное имя
+)
+
+// Имя листа оглавления
+const indexSheet = "Index"
+
+// Листы книги с постоянными именами. Листы групп получают другие имена, иначе строки группы
+// смешиваются со строками оглавления, сводки, графиков или широкого формата.
+var fixedSheets = []string{indexSheet, "Sheet1", summarySheet, chartsSheet, chartDataSheet, pivotSheet, pivotQualSheet}
+
+// Группа строки по имени переменной.
+//
+// Параметры:
+//
+// name - имя переменной, например "Dev3. HR. Тестовая переменная Word"
+func (m SplitMode) group(name string) string {
+
+	if m == SplitDevice {
+		if dev, _, ok := strings.Cut(name, "."); ok {
+			name = dev
+		}
+	}
+	return strings.TrimSpace(name)
+}
+
+// Сокращение имени до max символов.
+func truncName(name string, max int) string {
+
+	r := []rune(name)
+	if len(r) <= max {
+		return name
+	}
+	return string(r[:max])
+}
+
+// Имя листа, допустимое в Excel: без символов : \ / ? * [ ], без апострофа в начале и конце,
+// не длиннее 31 символа и не совпадающее с занятыми именами без учёта регистра.
+//
+// Параметры:
+//
+// name - исходное имя
+// used - занятые имена листов в нижнем регистре, дополняется новым именем
+func safeSheetName(name string, used map[string]bool) string {
+
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(strings.TrimSpace(name), "'")
+	if name == "" {
+		name = "Sheet"
+	}
+
+	// Имена, совпадающие с занятыми, дополняются номером
+	safe := truncName(name, excelize.MaxSheetNameLength)
+	for i := 2; used[strings.ToLower(safe)] || strings.EqualFold(safe, "History"); i++ {
+		suffix := fmt.Sprintf("~%d", i)
+		safe = truncName(name, excelize.MaxSheetNameLength-len(suffix)) + suffix
+	}
+
+	used[strings.ToLower(safe)] = true
+	return safe
+}
+
+// Запись строк по листам групп с оглавлением Index.
+type splitWriter struct {
+	file   *excelize.File
+	mode   SplitMode
+	header []any
+	used   map[string]bool         // занятые имена листов
+	groups map[string]*sheetWriter // запись листов группы
+	order  []string                // группы по порядку первого появления
+	rows   map[string]int          // количество строк группы
+}
+
+// Создание записи по листам групп. Первым добавляется лист оглавления.
+//
+// Параметры:
+//
+// file - книга
+// mode - разделение строк по листам
+// header - заголовки столбцов
+func newSplitWriter(file *excelize.File, mode SplitMode, header []any) (*splitWriter, error) {
+
+	s := &splitWriter{
+		file:   file,
+		mode:   mode,
+		header: header,
+		used:   make(map[string]bool, len(fixedSheets)),
+		groups: make(map[string]*sheetWriter),
+		rows:   make(map[string]int),
+	}
+	for _, sheet := range fixedSheets {
+		s.used[strings.ToLower(sheet)] = true
+	}
+
+	_, err := file.NewSheet(indexSheet)
+	if err != nil {
+		return nil, fmt.Errorf("xlsx -> ошибка при добавлении вкладки {%s}: {%v}", indexSheet, err)
+	}
+	return s, nil
+}
+
+// Запись строки значений на лист группы переменной.
+//
+// Параметры:
+//
+// name - имя переменной
+// values - значения ячеек строки
+func (s *splitWriter) add(name string, values []any) error {
+
+	group := s.mode.group(name)
+
+	sheets, ok := s.groups[group]
+	if !ok {
+		var err error
+		sheets, err = newSheetWriter(s.file, safeSheetName(group, s.used), s.header, true)
+		if err != nil {
+			return err
+		}
+		s.groups[group] = sheets
+		s.order = append(s.order, group)
+	}
+
+	s.rows[group]++
+	return sheets.add(values)
+}
+
+// Завершение записи листов групп и заполнение оглавления со ссылками на листы.
+func (s *splitWriter) flush() error {
+
+	for _, group := range s.order {
+		err := s.groups[group].flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := s.file.SetSheetRow(indexSheet, "A1", &[]any{"Sheet:", "Group:", "Rows:"})
+	if err != nil {
+		return fmt.Errorf("xlsx -> ошибка при добавлении заголовков вкладки {%s}: {%v}", indexSheet, err)
+	}
+
+	linkStyle, err := s.file.NewStyle(&excelize.Style{Font: &excelize.Font{Color: "1265BE", Underline: "single"}})
+	if err != nil {
+		return fmt.Errorf("xlsx -> ошибка стиля ссылок: {%v}", err)
+	}
+
+	row := 1
+	for _, group := range s.order {
+		for _, sheet := range s.groups[group].names {
+			row++
+			cell := fmt.Sprintf("A%d", row)
+
+			err = s.file.SetSheetRow(indexSheet, cell, &[]any{sheet, group, s.rows[group]})
+			if err != nil {
+				return fmt.Errorf("xlsx -> ошибка добавления строки {%d} во вкладку {%s}: {%v}", row, indexSheet, err)
+			}
+			err = s.file.SetCellHyperLink(indexSheet, cell, fmt.Sprintf("'%s'!A1", strings.ReplaceAll(sheet, "'", "''")), "Location")
+			if err != nil {
+				return fmt.Errorf("xlsx -> ошибка ссылки на вкладку {%s}: {%v}", sheet, err)
+			}
+			err = s.file.SetCellStyle(indexSheet, cell, cell, linkStyle)
+			if err != nil {
+				return fmt.Errorf("xlsx -> ошибка стиля ссылки на вкладку {%s}: {%v}", sheet, err)
+			}
+		}
+	}
+
+	return s.file.SetColWidth(indexSheet, "A", "B", 40)
+}
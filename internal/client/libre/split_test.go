@@ -0,0 +1,152 @@
+package libre
+
+import (
+	"bytes"
+	clientapi "clienthttps/internal/client/clientAPI"
+	"fmt"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// Допустимые имена листов
+func Test_safeSheetName(t *testing.T) {
+
+	used := map[string]bool{"index": true}
+
+	argData := []struct {
+		testName string
+		name     string
+		want     string
+	}{
+		{testName: "без изменений", name: "Dev1", want: "Dev1"},
+		{testName: "запрещённые символы", name: "Dev2/HR:[1]*?", want: "Dev2_HR__1___"},
+		{testName: "апострофы по краям", name: "'Dev3'", want: "Dev3"},
+		{testName: "длинное имя", name: "Dev3. HR. Тестовая переменная Word", want: "Dev3. HR. Тестовая переменная W"},
+		{testName: "совпадение после сокращения", name: "Dev3. HR. Тестовая переменная Wxyz", want: "Dev3. HR. Тестовая переменная~2"},
+		{testName: "совпадение без учёта регистра", name: "INDEX", want: "INDEX~2"},
+		{testName: "пустое имя", name: " ", want: "Sheet"},
+		{testName: "зарезервированное имя", name: "History", want: "History~2"},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+			got := safeSheetName(tt.name, used)
+			assert.Equal(t, tt.want, got)
+			assert.LessOrEqual(t, utf8.RuneCountInString(got), excelize.MaxSheetNameLength)
+		})
+	}
+}
+
+// Экспорт xlsx по листам устройств и переменных с оглавлением
+func Test_XLSX_Export_Split(t *testing.T) {
+
+	data := clientapi.RxDataDB{
+		StartDate: "2025-05-18",
+		Data: []clientapi.DataEl{
+			{Name: "Dev3. HR. Тестовая переменная Word", Value: "1672", Qual: "1"},
+			{Name: "Dev2. Coil. Тестовая переменная Bool", Value: "1", Qual: "1"},
+			{Name: "Dev3. Coil. Тестовая переменная Bool", Value: "0", Qual: "1"},
+			{Name: "Dev3. HR. Тестовая переменная Word", Value: "1673", Qual: "1"},
+			{Name: "Dev1/Real", Value: "1.5", Qual: "0"},
+		},
+	}
+
+	argData := []struct {
+		testName   string
+		mode       SplitMode
+		wantSheets []string
+		wantRows   map[string]int
+	}{
+		{
+			testName:   "по устройствам",
+			mode:       SplitDevice,
+			wantSheets: []string{"Index", "Dev3", "Dev2", "Dev1_Real"},
+			wantRows:   map[string]int{"Dev3": 3, "Dev2": 1, "Dev1_Real": 1},
+		},
+		{
+			testName:   "по переменным",
+			mode:       SplitTag,
+			wantSheets: []string{"Index", "Dev3. HR. Тестовая переменная W", "Dev2. Coil. Тестовая переменная", "Dev3. Coil. Тестовая переменная", "Dev1_Real"},
+			wantRows:   map[string]int{"Dev3. HR. Тестовая переменная W": 2, "Dev1_Real": 1},
+		},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+
+			var buf bytes.Buffer
+			n, err := XLSX{Split: tt.mode}.Export(&buf, Dataset{StartDate: data.StartDate, Rows: data.Rows()})
+			require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+			assert.Equal(t, len(data.Data), n)
+
+			file, err := excelize.OpenReader(&buf)
+			require.NoError(t, err)
+			defer file.Close()
+			assert.Equal(t, append(tt.wantSheets, "Summary"), file.GetSheetList())
+
+			for sheet, want := range tt.wantRows {
+				rows, err := file.GetRows(sheet)
+				require.NoError(t, err)
+				assert.Lenf(t, rows, want+1, "строки вкладки {%s}", sheet)
+			}
+
+			// Оглавление со ссылками на каждый лист
+			index, err := file.GetRows("Index")
+			require.NoError(t, err)
+			require.Len(t, index, len(tt.wantSheets))
+			for i, sheet := range tt.wantSheets[1:] {
+				assert.Equal(t, sheet, index[i+1][0])
+				ok, link, err := file.GetCellHyperLink("Index", fmt.Sprintf("A%d", i+2))
+				require.NoError(t, err)
+				assert.True(t, ok)
+				assert.Equal(t, "'"+sheet+"'!A1", link)
+			}
+		})
+	}
+}
+
+// Экспорт xlsx по листам переменных, имена которых совпадают с постоянными листами книги
+func Test_XLSX_Export_SplitFixedNames(t *testing.T) {
+
+	ts := "2025-05-18T03:01:02+07:00"
+	data := clientapi.RxDataDB{
+		StartDate: "2025-05-18",
+		Data: []clientapi.DataEl{
+			{Name: "Summary", Value: "1", Qual: "1", TimeStamp: ts},
+			{Name: "charts", Value: "2", Qual: "1", TimeStamp: ts},
+			{Name: "ChartData", Value: "3", Qual: "1", TimeStamp: ts},
+			{Name: "Pivot", Value: "4", Qual: "1", TimeStamp: ts},
+			{Name: "PivotQual", Value: "5", Qual: "1", TimeStamp: ts},
+			{Name: "Summary", Value: "6", Qual: "1", TimeStamp: ts},
+		},
+	}
+
+	var buf bytes.Buffer
+	xlsx := XLSX{Split: SplitTag, Chart: ChartTag, Pivot: &Pivot{Quality: QualitySheet}}
+	_, err := xlsx.Export(&buf, Dataset{StartDate: data.StartDate, Rows: data.Rows()})
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+
+	file, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+	defer file.Close()
+
+	groups := []string{"Summary~2", "charts~2", "ChartData~2", "Pivot~2", "PivotQual~2"}
+	assert.Subset(t, file.GetSheetList(), append(groups, "Summary", "Charts", "ChartData", "Pivot", "PivotQual"))
+
+	wantRows := map[string]int{"Summary~2": 2, "charts~2": 1, "ChartData~2": 1, "Pivot~2": 1, "PivotQual~2": 1}
+	for sheet, want := range wantRows {
+		rows, err := file.GetRows(sheet)
+		require.NoError(t, err)
+		assert.Lenf(t, rows, want+1, "строки вкладки {%s}", sheet)
+	}
+
+	// Постоянные листы не содержат строк групп
+	pivot, err := file.GetRows("Pivot")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TimeStamp:", "Summary", "charts", "ChartData", "Pivot", "PivotQual"}, pivot[0])
+	assert.Len(t, pivot, 2)
+}
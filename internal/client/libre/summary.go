@@ -0,0 +1,188 @@
+package libre
+
+import (
+	clientapi "clienthttps/internal/client/clientAPI"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Имя листа сводки
+const summarySheet = "Summary"
+
+// Статистика значений одной переменной
+type tagStats struct {
+	name     string
+	count    int       // количество строк
+	numbers  int       // количество числовых значений
+	min, max float64   // наименьшее и наибольшее числовое значение
+	sum      float64   // сумма числовых значений
+	first    time.Time // первая метка времени
+	last     time.Time // последняя метка времени
+	bad      int       // количество строк с качеством не 1
+}
+
+// Сборка сводки выгрузки: сведения о сервере и статистика по переменным.
+type summaryBuilder struct {
+	start time.Time            // начало приёма строк
+	end   time.Time            // окончание приёма строк
+	tags  []*tagStats          // переменные по порядку первого появления
+	index map[string]*tagStats // статистика по имени переменной
+}
+
+// Создание сборки сводки. Время выгрузки отсчитывается от создания.
+func newSummaryBuilder() *summaryBuilder {
+	return &summaryBuilder{start: time.Now(), index: make(map[string]*tagStats)}
+}
+
+// Учёт строки архивных данных.
+func (s *summaryBuilder) add(row clientapi.DataEl) {
+
+	st, ok := s.index[row.Name]
+	if !ok {
+		st = &tagStats{name: row.Name}
+		s.index[row.Name] = st
+		s.tags = append(s.tags, st)
+	}
+
+	st.count++
+	if strings.TrimSpace(row.Qual) != "1" {
+		st.bad++
+	}
+
+	if f, ok := numericValue(row.Name, row.Value); ok {
+		if st.numbers == 0 || f < st.min {
+			st.min = f
+		}
+		if st.numbers == 0 || f > st.max {
+			st.max = f
+		}
+		st.sum += f
+		st.numbers++
+	}
+
+	if t, ok := parseTimeStamp(row.TimeStamp); ok {
+		if st.first.IsZero() || t.Before(st.first) {
+			st.first = t
+		}
+		if t.After(st.last) {
+			st.last = t
+		}
+	}
+}
+
+// Числовое значение переменной для статистики. Логические значения учитываются как 0 и 1.
+// Возвращается значение и признак числового значения.
+func numericValue(name, v string) (float64, bool) {
+
+	switch val := (CellFormat{}).value(name, v).(type) {
+	case float64:
+		return val, true
+	case bool:
+		if val {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// Окончание приёма строк.
+func (s *summaryBuilder) done() {
+	s.end = time.Now()
+}
+
+// Строки листа сводки.
+//
+// Параметры:
+//
+// ds - набор данных
+// rows - количество записанных строк
+// cf - параметры записи значений в ячейки
+func (s *summaryBuilder) rows(ds Dataset, rows int, cf CellFormat) [][]any {
+
+	period := ds.StartDate
+	if ds.EndDate != "" && ds.EndDate != ds.StartDate {
+		period = ds.StartDate + "--" + ds.EndDate
+	}
+
+	var expected any = "-"
+	if ds.Expected != nil {
+		expected = *ds.Expected
+	}
+
+	exportDate := ds.ExportDate
+	if exportDate.IsZero() {
+		exportDate = s.end
+	}
+	if cf.Location != nil {
+		exportDate = exportDate.In(cf.Location)
+	}
+
+	out := [][]any{
+		{"Server:", ds.Server},
+		{"Server TimeStart:", ds.TimeStart},
+		{"Export date:", exportDate},
+		{"Period:", period},
+		{"Rows /cntstr:", expected},
+		{"Rows written:", rows},
+		{"Duration:", s.end.Sub(s.start).Round(time.Millisecond).String()},
+		{},
+		{"Name:", "Count:", "Min:", "Max:", "Mean:", "First TimeStamp:", "Last TimeStamp:", "Bad quality:"},
+	}
+
+	for _, st := range s.tags {
+		row := []any{st.name, st.count, nil, nil, nil, nil, nil, st.bad}
+		if st.numbers > 0 {
+			row[2], row[3], row[4] = st.min, st.max, st.sum/float64(st.numbers)
+		}
+		if !st.first.IsZero() {
+			row[5], row[6] = st.first, st.last
+			if cf.Location != nil {
+				row[5], row[6] = st.first.In(cf.Location), st.last.In(cf.Location)
+			}
+		}
+		out = append(out, row)
+	}
+
+	return out
+}
+
+// Запись листа сводки в книгу xlsx.
+//
+// Параметры:
+//
+// file - книга
+// ds - набор данных
+// rows - количество записанных строк
+// cf - параметры записи значений в ячейки
+// timeStyle - стиль ячеек времени
+func (s *summaryBuilder) writeXlsx(file *excelize.File, ds Dataset, rows int, cf CellFormat, timeStyle int) error {
+
+	_, err := file.NewSheet(summarySheet)
+	if err != nil {
+		return fmt.Errorf("xlsx -> ошибка при добавлении вкладки {%s}: {%v}", summarySheet, err)
+	}
+
+	for i, row := range s.rows(ds, rows, cf) {
+		for j, v := range row {
+			if v == nil {
+				continue
+			}
+			cell, _ := excelize.CoordinatesToCellName(j+1, i+1)
+			err = file.SetCellValue(summarySheet, cell, v)
+			if err == nil {
+				if _, ok := v.(time.Time); ok {
+					err = file.SetCellStyle(summarySheet, cell, cell, timeStyle)
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("xlsx -> ошибка добавления значения в ячейку {%s} вкладки {%s}: {%v}", cell, summarySheet, err)
+			}
+		}
+	}
+
+	return file.SetColWidth(summarySheet, "A", "A", 40)
+}
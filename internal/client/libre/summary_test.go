@@ -0,0 +1,93 @@
+package libre
+
+import (
+	"bytes"
+	clientapi "clienthttps/internal/client/clientAPI"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// Статистика по переменным
+func Test_summaryBuilder_stats(t *testing.T) {
+
+	s := newSummaryBuilder()
+	for _, row := range []clientapi.DataEl{
+		{Name: "Dev3. HR. Word", Value: "    10", Qual: "1", TimeStamp: "2025-05-18T03:01:07+07:00"},
+		{Name: "Dev3. HR. Word", Value: "30", Qual: "0", TimeStamp: "2025-05-18T03:01:02+07:00"},
+		{Name: "Dev3. HR. Word", Value: "нет связи", Qual: "0", TimeStamp: "нет времени"},
+		{Name: "Dev3. HR. Word", Value: "-4", Qual: "1", TimeStamp: "2025-05-18T03:01:12+07:00"},
+		{Name: "Dev3. HR. Word", Value: "NaN", Qual: "0", TimeStamp: "2025-05-18T03:01:10+07:00"},
+		{Name: "Dev2. Coil. Bool", Value: "1", Qual: "1", TimeStamp: "2025-05-18T03:01:02+07:00"},
+		{Name: "Dev2. Coil. Bool", Value: "0", Qual: "1", TimeStamp: "2025-05-18T03:01:07+07:00"},
+	} {
+		s.add(row)
+	}
+	s.done()
+
+	require.Len(t, s.tags, 2)
+
+	word := s.tags[0]
+	assert.Equal(t, "Dev3. HR. Word", word.name)
+	assert.Equal(t, 5, word.count)
+	assert.Equal(t, 3, word.numbers, "текст и NaN в статистику значений не входят")
+	assert.Equal(t, -4.0, word.min)
+	assert.Equal(t, 30.0, word.max)
+	assert.Equal(t, 12.0, word.sum/float64(word.numbers))
+	assert.Equal(t, 3, word.bad)
+	assert.Equal(t, "2025-05-18T03:01:02+07:00", word.first.Format(time.RFC3339), "первая метка - наименьшая")
+	assert.Equal(t, "2025-05-18T03:01:12+07:00", word.last.Format(time.RFC3339))
+
+	coil := s.tags[1]
+	assert.Equal(t, 0.0, coil.min, "логические значения учитываются как 0 и 1")
+	assert.Equal(t, 1.0, coil.max)
+	assert.Equal(t, 0, coil.bad)
+}
+
+// Экспорт xlsx - лист сводки
+func Test_XLSX_Export_Summary(t *testing.T) {
+
+	data := clientapi.RxDataDB{
+		StartDate: "2025-05-18",
+		EndDate:   "2025-05-19",
+		Data: []clientapi.DataEl{
+			{Name: "Dev3. HR. Word", Value: "10", Qual: "1", TimeStamp: "2025-05-18T03:01:07+07:00"},
+			{Name: "Dev3. HR. Word", Value: "20", Qual: "0", TimeStamp: "2025-05-18T03:01:12+07:00"},
+		},
+	}
+	expected := 3
+
+	ds := Dataset{
+		StartDate:  data.StartDate,
+		EndDate:    data.EndDate,
+		Rows:       data.Rows(),
+		Server:     "https://10.0.0.5:8443",
+		ExportDate: time.Date(2025, 5, 20, 10, 0, 0, 0, time.UTC),
+		TimeStart:  "2025-05-17 08:00:00",
+		Expected:   &expected,
+	}
+
+	var buf bytes.Buffer
+	_, err := XLSX{Cells: CellFormat{TimeFormat: "yyyy-mm-dd hh:mm:ss"}}.Export(&buf, ds)
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+
+	file, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+	defer file.Close()
+
+	rows, err := file.GetRows(summarySheet)
+	require.NoError(t, err)
+	require.Len(t, rows, 10)
+
+	assert.Equal(t, []string{"Server:", "https://10.0.0.5:8443"}, rows[0])
+	assert.Equal(t, []string{"Server TimeStart:", "2025-05-17 08:00:00"}, rows[1])
+	assert.Equal(t, []string{"Export date:", "2025-05-20 10:00:00"}, rows[2])
+	assert.Equal(t, []string{"Period:", "2025-05-18--2025-05-19"}, rows[3])
+	assert.Equal(t, []string{"Rows /cntstr:", "3"}, rows[4])
+	assert.Equal(t, []string{"Rows written:", "2"}, rows[5])
+	assert.Equal(t, "Duration:", rows[6][0])
+	assert.Equal(t, []string{"Dev3. HR. Word", "2", "10", "20", "15", "2025-05-18 03:01:07", "2025-05-18 03:01:12", "1"}, rows[9])
+}
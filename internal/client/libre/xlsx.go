@@ -0,0 +1,339 @@
+package libre
+
+import (
+	clientapi "clienthttps/internal/client/clientAPI"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Предельное количество строк листа xlsx, включая строку заголовков
+var sheetRows = excelize.TotalRows
+
+// Экспорт в книгу xlsx.
+type XLSX struct {
+	Cells CellFormat // параметры записи значений в ячейки
+	Pivot *Pivot     // лист широкого формата Pivot (nil - без листа)
+	Split SplitMode  // разделение строк по листам устройств или переменных
+	Chart ChartMode  // графики значений на листе Charts
+}
+
+// Расширение файла с точкой.
+func (XLSX) Ext() string {
+	return "." + FormatXLSX
+}
+
+// Запись строк набора в книгу за один проход по мере поступления из потока.
+// При превышении предельного количества строк листа запись продолжается на листах DataDB_2, DataDB_3 ...
+// В конец книги добавляется лист Summary со сведениями о выгрузке и статистикой по переменным.
+// Возвращается количество записанных строк и ошибка.
+//
+// Параметры:
+//
+// w - получатель книги
+// ds - набор данных
+func (x XLSX) Export(w io.Writer, ds Dataset) (rows int, err error) {
+
+	// Книга формируется в памяти и временных файлах excelize, в w записывается после приёма всех строк
+	file := excelize.NewFile()
+	defer file.Close()
+
+	// Формирование заголовков
+	// Name: Value:	Quality: TimeStamp:
+	header := make([]any, len(columns))
+	for i, v := range columns {
+		header[i] = v
+	}
+	// Строки на листе DataDB или на листах групп с оглавлением
+	var sheets *sheetWriter
+	var split *splitWriter
+	if x.Split == SplitNone {
+		sheets, err = newSheetWriter(file, "DataDB", header, true)
+	} else {
+		split, err = newSplitWriter(file, x.Split, header)
+	}
+	if err != nil {
+		return 0, err
+	}
+	err = file.DeleteSheet("Sheet1")
+	if err != nil {
+		return 0, errors.New("xlsx -> ошибка при удалении вкладки Sheet1")
+	}
+
+	styles, err := newXlsxStyles(file, x.Cells)
+	if err != nil {
+		return 0, err
+	}
+	timeStyle := styles.time
+
+	summary := newSummaryBuilder()
+
+	var pivot *pivotBuilder
+	if x.Pivot != nil {
+		pivot = newPivotBuilder(*x.Pivot)
+	}
+
+	var chart *chartBuilder
+	if x.Chart != ChartNone {
+		chart = newChartBuilder(x.Chart)
+	}
+
+	// Перенос данных
+	for str, errRow := range ds.Rows {
+
+		if errRow != nil {
+			return rows, errSource(rows, errRow)
+		}
+
+		values := x.dataRow(str, styles)
+		if split != nil {
+			err = split.add(str.Name, values)
+		} else {
+			err = sheets.add(values)
+		}
+		if err != nil {
+			return rows, err
+		}
+		rows++
+
+		summary.add(str)
+		if pivot != nil {
+			pivot.add(str)
+		}
+		if chart != nil {
+			chart.add(str)
+		}
+	}
+	summary.done()
+
+	if split != nil {
+		err = split.flush()
+	} else {
+		err = sheets.flush()
+	}
+	if err != nil {
+		return rows, err
+	}
+
+	if pivot != nil {
+		err = pivot.write(file, x.Cells, timeStyle)
+		if err != nil {
+			return rows, err
+		}
+	}
+
+	if chart != nil {
+		err = chart.write(file, x.Cells, timeStyle)
+		if err != nil {
+			return rows, err
+		}
+	}
+
+	err = summary.writeXlsx(file, ds, rows, x.Cells, timeStyle)
+	if err != nil {
+		return rows, err
+	}
+
+	_, err = file.WriteTo(w)
+	if err != nil {
+		return rows, fmt.Errorf("xlsx -> ошибка при сохранении книги: {%v}", err)
+	}
+	return rows, nil
+}
+
+// Стили ячеек книги
+type xlsxStyles struct {
+	time    int // ячейка времени
+	bad     int // ячейка строки с плохим качеством
+	badTime int // ячейка времени строки с плохим качеством
+}
+
+// Создание стилей ячеек книги. Возвращаются стили и ошибка.
+//
+// Параметры:
+//
+// file - книга
+// cf - параметры записи значений в ячейки
+func newXlsxStyles(file *excelize.File, cf CellFormat) (st xlsxStyles, err error) {
+
+	badFill := excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFC7CE"}}
+
+	st.time, err = file.NewStyle(&excelize.Style{CustomNumFmt: ptr(cf.timeFormat())})
+	if err != nil {
+		return st, fmt.Errorf("xlsx -> ошибка формата ячеек времени {%s}: {%v}", cf.timeFormat(), err)
+	}
+	st.bad, err = file.NewStyle(&excelize.Style{Fill: badFill})
+	if err != nil {
+		return st, fmt.Errorf("xlsx -> ошибка стиля строк с плохим качеством: {%v}", err)
+	}
+	st.badTime, err = file.NewStyle(&excelize.Style{Fill: badFill, CustomNumFmt: ptr(cf.timeFormat())})
+	if err != nil {
+		return st, fmt.Errorf("xlsx -> ошибка стиля строк с плохим качеством: {%v}", err)
+	}
+	return st, nil
+}
+
+// Значения ячеек строки листа архивных данных. Строки с качеством не 1 выделяются заливкой,
+// чтобы обрывы связи с устройствами Modbus были видны сразу.
+//
+// Параметры:
+//
+// str - строка архивных данных
+// st - стили ячеек книги
+func (x XLSX) dataRow(str clientapi.DataEl, st xlsxStyles) []any {
+
+	values := []any{str.Name, x.Cells.value(str.Name, str.Value), x.Cells.quality(str.Qual), x.Cells.timeStamp(str.TimeStamp)}
+
+	bad := strings.TrimSpace(str.Qual) != "1"
+	for i, v := range values {
+		_, isTime := v.(time.Time)
+		switch {
+		case isTime && bad:
+			values[i] = excelize.Cell{StyleID: st.badTime, Value: v}
+		case isTime:
+			values[i] = excelize.Cell{StyleID: st.time, Value: v}
+		case bad:
+			values[i] = excelize.Cell{StyleID: st.bad, Value: v}
+		}
+	}
+	return values
+}
+
+// Потоковая запись строк в листы книги. При заполнении листа запись продолжается
+// на следующем листе с тем же заголовком: DataDB, DataDB_2, DataDB_3 ...
+type sheetWriter struct {
+	file   *excelize.File
+	base   string                 // имя первого листа
+	header []any                  // заголовки столбцов
+	sw     *excelize.StreamWriter // запись текущего листа
+	num    int                    // номер текущего листа
+	row    int                    // последняя записанная строка текущего листа
+	names  []string               // имена записанных листов
+	data   bool                   // лист архивных данных: закреплённая строка заголовков и автофильтр
+}
+
+// Создание записи листов с добавлением первого листа base.
+//
+// Параметры:
+//
+// file - книга
+// base - имя первого листа
+// header - заголовки столбцов
+// data - лист архивных данных: закреплённая строка заголовков и автофильтр
+func newSheetWriter(file *excelize.File, base string, header []any, data bool) (*sheetWriter, error) {
+
+	s := &sheetWriter{file: file, base: base, header: header, data: data}
+
+	err := s.next()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Имя текущего листа. Имя первого листа сокращается, чтобы номер листа умещался в 31 символ.
+func (s *sheetWriter) sheet() string {
+	if s.num <= 1 {
+		return s.base
+	}
+	suffix := fmt.Sprintf("_%d", s.num)
+	return truncName(s.base, excelize.MaxSheetNameLength-len(suffix)) + suffix
+}
+
+// Завершение текущего листа и переход к следующему с записью заголовков.
+func (s *sheetWriter) next() error {
+
+	err := s.flush()
+	if err != nil {
+		return err
+	}
+
+	s.num++
+	_, err = s.file.NewSheet(s.sheet())
+	if err != nil {
+		return fmt.Errorf("xlsx -> ошибка при добавлении вкладки {%s}: {%v}", s.sheet(), err)
+	}
+	s.names = append(s.names, s.sheet())
+
+	s.sw, err = s.file.NewStreamWriter(s.sheet())
+	if err != nil {
+		return fmt.Errorf("xlsx -> ошибка записи вкладки {%s}: {%v}", s.sheet(), err)
+	}
+
+	// Строка заголовков закрепляется до записи строк
+	if s.data {
+		err = s.sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+		if err != nil {
+			return fmt.Errorf("xlsx -> ошибка закрепления заголовков вкладки {%s}: {%v}", s.sheet(), err)
+		}
+	}
+
+	s.row = 1
+	err = s.sw.SetRow("A1", s.header)
+	if err != nil {
+		return fmt.Errorf("xlsx -> ошибка при добавлении заголовков вкладки {%s}: {%v}", s.sheet(), err)
+	}
+	return nil
+}
+
+// Запись строки значений. Возвращается ошибка.
+//
+// Параметры:
+//
+// values - значения ячеек строки
+func (s *sheetWriter) add(values []any) error {
+
+	if s.row >= sheetRows {
+		err := s.next()
+		if err != nil {
+			return err
+		}
+	}
+
+	s.row++
+	cell, err := excelize.CoordinatesToCellName(1, s.row)
+	if err != nil {
+		return fmt.Errorf("xlsx -> ошибка адреса строки {%d}: {%v}", s.row, err)
+	}
+
+	err = s.sw.SetRow(cell, values)
+	if err != nil {
+		return fmt.Errorf("xlsx -> ошибка добавления строки {%d} во вкладку {%s}: {%v}", s.row, s.sheet(), err)
+	}
+	return nil
+}
+
+// Завершение записи текущего листа.
+func (s *sheetWriter) flush() error {
+
+	if s.sw == nil {
+		return nil
+	}
+
+	// Автофильтр по всем записанным строкам
+	if s.data {
+		last, err := excelize.CoordinatesToCellName(len(s.header), max(s.row, 2))
+		if err == nil {
+			err = s.sw.AddTable(&excelize.Table{Range: "A1:" + last, ShowRowStripes: ptr(false)})
+		}
+		if err != nil {
+			return fmt.Errorf("xlsx -> ошибка автофильтра вкладки {%s}: {%v}", s.sheet(), err)
+		}
+	}
+
+	err := s.sw.Flush()
+	s.sw = nil
+	if err != nil {
+		return fmt.Errorf("xlsx -> ошибка завершения записи вкладки {%s}: {%v}", s.sheet(), err)
+	}
+	return nil
+}
+
+// Указатель на значение.
+func ptr[T any](v T) *T {
+	return &v
+}
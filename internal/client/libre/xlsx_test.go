@@ -0,0 +1,126 @@
+package libre
+
+import (
+	"bytes"
+	clientapi "clienthttps/internal/client/clientAPI"
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// Сохранение потока строк в xlsx - переход на следующий лист при заполнении
+func Test_SaveStreamXlsx_Rollover(t *testing.T) {
+
+	// Лист вмещает заголовок и 4 строки данных
+	limit := sheetRows
+	sheetRows = 5
+	t.Cleanup(func() { sheetRows = limit })
+
+	data := clientapi.RxDataDB{StartDate: "2025-05-18"}
+	for i := 0; i < 10; i++ {
+		data.Data = append(data.Data, clientapi.DataEl{Name: "Tag", Value: strconv.Itoa(i), Qual: "1", TimeStamp: "2025-05-18T03:01:02+07:00"})
+	}
+
+	fileName, n, err := SaveStreamXlsx(Dataset{StartDate: data.StartDate, Rows: data.Rows()})
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, 10, n)
+	defer os.Remove(fileName)
+
+	file, err := excelize.OpenFile(fileName)
+	require.NoError(t, err)
+	defer file.Close()
+
+	assert.Equal(t, []string{"DataDB", "DataDB_2", "DataDB_3", "Summary"}, file.GetSheetList())
+
+	argData := []struct {
+		sheet  string
+		values []string
+	}{
+		{sheet: "DataDB", values: []string{"0", "1", "2", "3"}},
+		{sheet: "DataDB_2", values: []string{"4", "5", "6", "7"}},
+		{sheet: "DataDB_3", values: []string{"8", "9"}},
+	}
+
+	for _, tt := range argData {
+		rows, err := file.GetRows(tt.sheet)
+		require.NoError(t, err)
+		require.Lenf(t, rows, len(tt.values)+1, "вкладка {%s}", tt.sheet)
+		assert.Equal(t, []string{"Name:", "Value:", "Quality:", "TimeStamp:"}, rows[0], "заголовок на каждой вкладке")
+		for i, v := range tt.values {
+			assert.Equal(t, v, rows[i+1][1], fmt.Sprintf("вкладка {%s}, строка {%d}", tt.sheet, i+2))
+		}
+	}
+}
+
+// Сохранение в xlsx - выделение строк с плохим качеством, автофильтр и закреплённый заголовок
+func Test_XLSX_DataSheetView(t *testing.T) {
+
+	ds := Dataset{StartDate: "2025-05-18", Rows: clientapi.RxDataDB{Data: []clientapi.DataEl{
+		{Name: "Dev.Tag", Value: "1.5", Qual: "1", TimeStamp: "2025-05-18T03:01:02+07:00"},
+		{Name: "Dev.Tag", Value: "0", Qual: "0", TimeStamp: "2025-05-18T03:01:03+07:00"},
+		{Name: "Dev.Tag", Value: "2.5", Qual: " 1 ", TimeStamp: "2025-05-18T03:01:04+07:00"},
+	}}.Rows()}
+
+	var buf bytes.Buffer
+	n, err := XLSX{}.Export(&buf, ds)
+	require.NoErrorf(t, err, "ожидалось отсутствие ошибки, а принято: {%v}", err)
+	assert.Equal(t, 3, n)
+
+	file, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+	defer file.Close()
+
+	// Закреплённая строка заголовков
+	panes, err := file.GetPanes("DataDB")
+	require.NoError(t, err)
+	assert.True(t, panes.Freeze)
+	assert.Equal(t, 1, panes.YSplit)
+	assert.Equal(t, "A2", panes.TopLeftCell)
+
+	// Автофильтр по всем строкам
+	tables, err := file.GetTables("DataDB")
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+	assert.Equal(t, "A1:D4", tables[0].Range)
+
+	// Заливка только строки с плохим качеством
+	argData := []struct {
+		testName string
+		row      int
+		bad      bool
+	}{
+		{testName: "качество 1", row: 2, bad: false},
+		{testName: "качество 0", row: 3, bad: true},
+		{testName: "качество 1 с пробелами", row: 4, bad: false},
+	}
+
+	for _, tt := range argData {
+		t.Run(tt.testName, func(t *testing.T) {
+			for _, col := range []string{"A", "B", "C", "D"} {
+				cell := col + strconv.Itoa(tt.row)
+				id, err := file.GetCellStyle("DataDB", cell)
+				require.NoError(t, err)
+				style, err := file.GetStyle(id)
+				require.NoError(t, err)
+				if tt.bad {
+					assert.Equalf(t, []string{"FFC7CE"}, style.Fill.Color, "ячейка {%s}", cell)
+				} else {
+					assert.Emptyf(t, style.Fill.Color, "ячейка {%s}", cell)
+				}
+			}
+
+			// Формат времени сохраняется вместе с заливкой
+			id, err := file.GetCellStyle("DataDB", "D"+strconv.Itoa(tt.row))
+			require.NoError(t, err)
+			style, err := file.GetStyle(id)
+			require.NoError(t, err)
+			require.NotNil(t, style.CustomNumFmt)
+			assert.Equal(t, DefaultTimeFormat, *style.CustomNumFmt)
+		})
+	}
+}